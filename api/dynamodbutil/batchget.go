@@ -0,0 +1,320 @@
+package dynamodbutil
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// MaxBatchGetSize - Maximum number of keys in a single BatchGetItem call
+const MaxBatchGetSize = 100
+
+// tableKey - A requested key along with the table it belongs to
+type tableKey struct {
+	table string
+	key   map[string]*db.AttributeValue
+}
+
+// BatchGetter - Queues keys for any number of tables and reads them with BatchGetItem on Execute
+// Keys are deduplicated, split into calls of MaxBatchGetSize keys and UnprocessedKeys are retried with backoff
+type BatchGetter struct {
+	batchConfig
+	client         dynamodbiface.DynamoDBAPI
+	keyNames       keyNameCache
+	consistentRead bool
+	mu             sync.Mutex
+	keys           []tableKey
+}
+
+// NewBatchGetter - Creates a BatchGetter using the given client
+func NewBatchGetter(client dynamodbiface.DynamoDBAPI, opts ...BatchOption) *BatchGetter {
+	return &BatchGetter{
+		batchConfig: newBatchConfig(opts),
+		client:      client,
+	}
+}
+
+// ConsistentRead - Makes every read strongly consistent
+func (g *BatchGetter) ConsistentRead() *BatchGetter {
+	g.consistentRead = true
+	return g
+}
+
+// Get - Queues a read of the key from the table
+func (g *BatchGetter) Get(tableName string, key map[string]*db.AttributeValue) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.keys = append(g.keys, tableKey{table: tableName, key: key})
+}
+
+// GetItem - Queues a read of the item's key from the table named by its TableOptions, see KeyFromStruct
+func (g *BatchGetter) GetItem(item interface{}) error {
+	key, err := KeyFromStruct(item)
+	if err != nil {
+		return err
+	}
+	table := TableNameFromStruct(item)
+	g.keyNames.set(table, sortedNames(key))
+	g.Get(table, key)
+	return nil
+}
+
+// Len - Returns the number of queued keys
+func (g *BatchGetter) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.keys)
+}
+
+// BatchGetResult - Outcome of an Execute, items are matched back to the keys they were requested with
+type BatchGetResult struct {
+	keys     []tableKey
+	keyNames map[string][]string
+	items    map[string]map[string]map[string]*db.AttributeValue
+	// Failed is the number of distinct keys that could not be read, they are listed in Unprocessed
+	Failed      int
+	Unprocessed map[string][]map[string]*db.AttributeValue
+}
+
+// Items - Returns the items read from the table in the order their keys were queued, keys without an item are skipped
+func (r *BatchGetResult) Items(tableName string) []map[string]*db.AttributeValue {
+	items := []map[string]*db.AttributeValue{}
+	for _, k := range r.keys {
+		if k.table != tableName {
+			continue
+		}
+		if item, ok := r.items[tableName][keySignature(r.keyNames[tableName], k.key)]; ok {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Item - Returns the item read for the key, if any
+func (r *BatchGetResult) Item(tableName string, key map[string]*db.AttributeValue) (map[string]*db.AttributeValue, bool) {
+	item, ok := r.items[tableName][keySignature(r.keyNames[tableName], key)]
+	return item, ok
+}
+
+// Unmarshal - Unmarshals the items read from the table into out, a pointer to a slice, in the order their keys were queued
+func (r *BatchGetResult) Unmarshal(tableName string, out interface{}) error {
+	return dynamodbattribute.UnmarshalListOfMaps(r.Items(tableName), out)
+}
+
+// Execute - Reads every queued key, the queue is emptied even if some reads fail
+func (g *BatchGetter) Execute(ctx context.Context) (*BatchGetResult, error) {
+	g.mu.Lock()
+	keys := g.keys
+	g.keys = nil
+	g.mu.Unlock()
+
+	result := &BatchGetResult{
+		keys:        keys,
+		keyNames:    map[string][]string{},
+		items:       map[string]map[string]map[string]*db.AttributeValue{},
+		Unprocessed: map[string][]map[string]*db.AttributeValue{},
+	}
+	var lastErr error
+
+	// BatchGetItem rejects duplicate keys, so every key is only requested once
+	// Keys of tables whose key schema cannot be read are not requested and count as failed
+	unique := []tableKey{}
+	seen := map[string]bool{}
+	failedTables := map[string]bool{}
+	for _, k := range keys {
+		if _, ok := result.keyNames[k.table]; !ok && !failedTables[k.table] {
+			names, err := g.keyNames.get(ctx, g.client, k.table)
+			if err != nil {
+				lastErr = err
+				failedTables[k.table] = true
+			} else {
+				result.keyNames[k.table] = names
+			}
+		}
+		names := result.keyNames[k.table]
+		if failedTables[k.table] {
+			names = sortedNames(k.key)
+		}
+		sig := k.table + "|" + keySignature(names, k.key)
+		if seen[sig] {
+			continue
+		}
+		seen[sig] = true
+		if failedTables[k.table] {
+			result.Failed++
+			result.Unprocessed[k.table] = append(result.Unprocessed[k.table], k.key)
+			continue
+		}
+		unique = append(unique, k)
+	}
+
+	batches := make(chan []tableKey)
+	go func() {
+		defer close(batches)
+		for i := 0; i < len(unique); i += MaxBatchGetSize {
+			j := i + MaxBatchGetSize
+			if j > len(unique) {
+				j = len(unique)
+			}
+			batches <- unique[i:j]
+		}
+	}()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < g.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				items, unprocessed, err := g.getBatch(ctx, batch, result.keyNames)
+				mu.Lock()
+				for table, tableItems := range items {
+					if result.items[table] == nil {
+						result.items[table] = map[string]map[string]*db.AttributeValue{}
+					}
+					for sig, item := range tableItems {
+						result.items[table][sig] = item
+					}
+				}
+				for _, k := range unprocessed {
+					result.Failed++
+					result.Unprocessed[k.table] = append(result.Unprocessed[k.table], k.key)
+				}
+				if err != nil {
+					lastErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if result.Failed > 0 {
+		if lastErr != nil {
+			return result, fmt.Errorf("Error: %d keys could not be read: %w", result.Failed, lastErr)
+		}
+		return result, fmt.Errorf("Error: %d keys were still unprocessed after retrying", result.Failed)
+	}
+	return result, nil
+}
+
+// getBatch - Reads a single batch, retrying unprocessed keys and throttling errors until maxRetries is reached
+// Items are returned per table, keyed by the signature of their key attributes as named in keyNames
+func (g *BatchGetter) getBatch(ctx context.Context, batch []tableKey, keyNames map[string][]string) (map[string]map[string]map[string]*db.AttributeValue, []tableKey, error) {
+	items := map[string]map[string]map[string]*db.AttributeValue{}
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return items, batch, err
+		}
+		requestItems := map[string]*db.KeysAndAttributes{}
+		for _, k := range batch {
+			if requestItems[k.table] == nil {
+				requestItems[k.table] = &db.KeysAndAttributes{}
+				if g.consistentRead {
+					requestItems[k.table].ConsistentRead = aws.Bool(true)
+				}
+			}
+			requestItems[k.table].Keys = append(requestItems[k.table].Keys, k.key)
+		}
+		out, err := g.client.BatchGetItemWithContext(ctx, &db.BatchGetItemInput{RequestItems: requestItems})
+		if err != nil && !isThrottlingError(err) {
+			return items, batch, err
+		}
+		if err == nil {
+			for table, tableItems := range out.Responses {
+				if items[table] == nil {
+					items[table] = map[string]map[string]*db.AttributeValue{}
+				}
+				for _, item := range tableItems {
+					items[table][keySignature(keyNames[table], item)] = item
+				}
+			}
+			batch = batch[:0:0]
+			for table, unprocessed := range out.UnprocessedKeys {
+				for _, key := range unprocessed.Keys {
+					batch = append(batch, tableKey{table: table, key: key})
+				}
+			}
+			if len(batch) == 0 {
+				return items, nil, nil
+			}
+		}
+		if attempt >= g.maxRetries {
+			return items, batch, err
+		}
+		if err := sleep(ctx, g.backoff(attempt)); err != nil {
+			return items, batch, err
+		}
+	}
+}
+
+// keySignature - Identifies an item by the values of the named attributes, names must be sorted
+func keySignature(names []string, item map[string]*db.AttributeValue) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		av := item[name]
+		switch {
+		case av == nil:
+			parts[i] = name + "="
+		case av.S != nil:
+			parts[i] = name + "=S:" + aws.StringValue(av.S)
+		case av.N != nil:
+			parts[i] = name + "=N:" + CanonicalNumber(aws.StringValue(av.N))
+		case av.B != nil:
+			parts[i] = name + "=B:" + base64.StdEncoding.EncodeToString(av.B)
+		default:
+			parts[i] = name + "=" + av.String()
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// CanonicalNumber - Returns a single representation for equal numbers, e.g. 1.50, 1.5 and 15e-1 all give 3/2
+// DynamoDB compares N values numerically, strings that aren't numbers are returned unchanged
+func CanonicalNumber(n string) string {
+	r, ok := new(big.Rat).SetString(n)
+	if !ok {
+		return n
+	}
+	return r.RatString()
+}
+
+// KeyFromStruct - Marshals the fields of s tagged with a Key Type into a key, see CreateTableInputFromStruct
+func KeyFromStruct(s interface{}) (map[string]*db.AttributeValue, error) {
+	av, err := dynamodbattribute.MarshalMap(s)
+	if err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf(s)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Error: Input must be a struct or pointer to a struct")
+	}
+	key := map[string]*db.AttributeValue{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if _, ok := lookupTag(f, KeyTypeTags); !ok {
+			continue
+		}
+		v, ok := av[f.Name]
+		if !ok {
+			return nil, fmt.Errorf("Error: key attribute %s of %s is empty", f.Name, t.Name())
+		}
+		key[f.Name] = v
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("Error: %s has no fields tagged with a Key Type", t.Name())
+	}
+	return key, nil
+}
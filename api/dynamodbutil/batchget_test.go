@@ -0,0 +1,161 @@
+package dynamodbutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil/dynamodbtest"
+)
+
+func newStoredPrices(t *testing.T, symbol string, days int) *dynamodbtest.DB {
+	t.Helper()
+	d := newPrices(t)
+	if _, err := flushPrices(t, d, context.Background(), prices(symbol, days)); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestBatchGetterChunksAndKeepsKeyOrder(t *testing.T) {
+	d := newStoredPrices(t, "AAPL", 250)
+	g := dynamodbutil.NewBatchGetter(d, fastRetries(dynamodbutil.WithConcurrency(1))...)
+	// queued newest first, plus a day that was never stored
+	for day := 251; day >= 1; day-- {
+		if err := g.GetItem(&Price{Symbol: "AAPL", Day: int64(day)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls := d.Calls(dynamodbtest.OpBatchGetItem); calls != 3 {
+		t.Fatalf("got %d BatchGetItem calls, want 3 batches of at most %d", calls, dynamodbutil.MaxBatchGetSize)
+	}
+	out := []*Price{}
+	if err := res.Unmarshal(pricesTable, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 250 {
+		t.Fatalf("got %d prices, want 250", len(out))
+	}
+	for i, p := range out {
+		if p.Day != int64(250-i) {
+			t.Fatalf("got day %d at %d, want the order the keys were queued in", p.Day, i)
+		}
+	}
+	if calls := d.Calls(dynamodbtest.OpDescribeTable); calls != 0 {
+		t.Fatalf("got %d DescribeTable calls, want 0 as the key names come from the struct tags", calls)
+	}
+}
+
+func TestBatchGetterDedupesKeys(t *testing.T) {
+	d := newStoredPrices(t, "AAPL", 3)
+	g := dynamodbutil.NewBatchGetter(d, fastRetries()...)
+	// 1, 1.0 and 10e-1 are the same number, BatchGetItem rejects the call if a key is requested twice
+	for _, day := range []string{"1", "2", "1.0", "10e-1", "2"} {
+		g.Get(pricesTable, priceKey("AAPL", day))
+	}
+	res, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, item := range res.Items(pricesTable) {
+		got = append(got, *item["Day"].N)
+	}
+	if fmt.Sprint(got) != "[1 2 1 1 2]" {
+		t.Fatalf("got days %v, want an item for every queued key", got)
+	}
+	if calls := d.Calls(dynamodbtest.OpBatchGetItem); calls != 1 {
+		t.Fatalf("got %d BatchGetItem calls, want 1", calls)
+	}
+}
+
+func TestBatchGetterMatchesNumbersByValue(t *testing.T) {
+	d := newPrices(t)
+	item := priceKey("AAPL", "1.5")
+	item["Close"] = &db.AttributeValue{N: aws.String("10")}
+	if _, err := d.PutItem(&db.PutItemInput{TableName: aws.String(pricesTable), Item: item}); err != nil {
+		t.Fatal(err)
+	}
+	g := dynamodbutil.NewBatchGetter(d)
+	g.Get(pricesTable, priceKey("AAPL", "1.50"))
+	res, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items := res.Items(pricesTable); len(items) != 1 {
+		t.Fatalf("got %d items, want the item stored as 1.5", len(items))
+	}
+	if _, ok := res.Item(pricesTable, priceKey("AAPL", "15e-1")); !ok {
+		t.Fatal("got no item for 15e-1, want the item stored as 1.5")
+	}
+	// raw keys don't name their key attributes, so the table is described once
+	if calls := d.Calls(dynamodbtest.OpDescribeTable); calls != 1 {
+		t.Fatalf("got %d DescribeTable calls, want 1", calls)
+	}
+}
+
+func TestBatchGetterRetriesUnprocessedKeys(t *testing.T) {
+	d := newStoredPrices(t, "AAPL", 50)
+	d.Inject(dynamodbtest.OpBatchGetItem, dynamodbtest.Fault{Unprocessed: 30, Times: 2})
+	g := dynamodbutil.NewBatchGetter(d, fastRetries()...)
+	for _, p := range prices("AAPL", 50) {
+		if err := g.GetItem(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(res.Items(pricesTable)); n != 50 || res.Failed != 0 {
+		t.Fatalf("got %d items and %d failed, want 50 and 0", n, res.Failed)
+	}
+	if calls := d.Calls(dynamodbtest.OpBatchGetItem); calls != 3 {
+		t.Fatalf("got %d BatchGetItem calls, want 3", calls)
+	}
+}
+
+func TestBatchGetterMaxRetries(t *testing.T) {
+	d := newStoredPrices(t, "AAPL", 10)
+	d.Inject(dynamodbtest.OpBatchGetItem, dynamodbtest.UnprocessedFault(4))
+	g := dynamodbutil.NewBatchGetter(d, fastRetries(dynamodbutil.WithMaxRetries(2))...)
+	for _, p := range prices("AAPL", 10) {
+		if err := g.GetItem(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := g.Execute(context.Background())
+	if err == nil {
+		t.Fatal("got no error, want the unprocessed keys reported")
+	}
+	if n := len(res.Items(pricesTable)); n != 6 || res.Failed != 4 || len(res.Unprocessed[pricesTable]) != 4 {
+		t.Fatalf("got %d items, %d failed and %d unprocessed, want 6, 4 and 4", n, res.Failed, len(res.Unprocessed[pricesTable]))
+	}
+	if calls := d.Calls(dynamodbtest.OpBatchGetItem); calls != 3 {
+		t.Fatalf("got %d BatchGetItem calls, want the first and 2 retries", calls)
+	}
+}
+
+func TestBatchGetterMissingTable(t *testing.T) {
+	d := newStoredPrices(t, "AAPL", 2)
+	g := dynamodbutil.NewBatchGetter(d, fastRetries()...)
+	g.Get("Missing", priceKey("AAPL", "1"))
+	g.Get(pricesTable, priceKey("AAPL", "1"))
+	res, err := g.Execute(context.Background())
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) || aerr.Code() != db.ErrCodeResourceNotFoundException {
+		t.Fatalf("got error %v, want the missing table reported", err)
+	}
+	if res.Failed != 1 || len(res.Unprocessed["Missing"]) != 1 || len(res.Items(pricesTable)) != 1 {
+		t.Fatalf("got %d failed and %d items, want the missing table's key failed and the other read", res.Failed, len(res.Items(pricesTable)))
+	}
+}
@@ -0,0 +1,395 @@
+package dynamodbutil
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+const (
+	DefaultBatchConcurrency = 4
+	DefaultBatchMaxRetries  = 8
+	DefaultBatchBackoff     = 50 * time.Millisecond
+	DefaultBatchMaxBackoff  = 5 * time.Second
+)
+
+// batchConfig - Settings shared by BatchWriter and BatchGetter
+type batchConfig struct {
+	concurrency    int
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func newBatchConfig(opts []BatchOption) batchConfig {
+	c := batchConfig{
+		concurrency:    DefaultBatchConcurrency,
+		maxRetries:     DefaultBatchMaxRetries,
+		initialBackoff: DefaultBatchBackoff,
+		maxBackoff:     DefaultBatchMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// keyNameCache - Key attribute names per table, taken from the struct tags or the table's KeySchema the first time a table is seen
+type keyNameCache struct {
+	mu    sync.Mutex
+	names map[string][]string
+}
+
+func (c *keyNameCache) set(table string, names []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.names == nil {
+		c.names = map[string][]string{}
+	}
+	if _, ok := c.names[table]; !ok {
+		c.names[table] = names
+	}
+}
+
+// get - Returns the sorted key attribute names of the table, describing it if it hasn't been seen yet
+func (c *keyNameCache) get(ctx context.Context, client dynamodbiface.DynamoDBAPI, table string) ([]string, error) {
+	c.mu.Lock()
+	names, ok := c.names[table]
+	c.mu.Unlock()
+	if ok {
+		return names, nil
+	}
+	out, err := client.DescribeTableWithContext(ctx, &db.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return nil, fmt.Errorf("Error: unable to read the key schema of %s: %w", table, err)
+	}
+	names = []string{}
+	for _, k := range out.Table.KeySchema {
+		names = append(names, aws.StringValue(k.AttributeName))
+	}
+	sort.Strings(names)
+	c.set(table, names)
+	return names, nil
+}
+
+// sortedNames - Returns the attribute names of the key in order
+func sortedNames(key map[string]*db.AttributeValue) []string {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BatchOption - Configures a BatchWriter or BatchGetter
+type BatchOption func(*batchConfig)
+
+// WithConcurrency - Caps the number of batch calls in flight, values below 1 are ignored
+func WithConcurrency(n int) BatchOption {
+	return func(c *batchConfig) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+// WithMaxRetries - Sets how many times unprocessed requests and throttled batches are retried before they count as failed
+func WithMaxRetries(n int) BatchOption {
+	return func(c *batchConfig) {
+		if n >= 0 {
+			c.maxRetries = n
+		}
+	}
+}
+
+// WithBatchBackoff - Sets the initial and maximum delay between retries, the delay doubles every attempt
+func WithBatchBackoff(initial, max time.Duration) BatchOption {
+	return func(c *batchConfig) {
+		if initial > 0 {
+			c.initialBackoff = initial
+		}
+		if max >= initial {
+			c.maxBackoff = max
+		}
+	}
+}
+
+// tableWriteRequest - A pending write along with the table it belongs to, batches may span several tables
+type tableWriteRequest struct {
+	table   string
+	request *db.WriteRequest
+}
+
+// BatchWriter - Queues puts and deletes for any number of tables and writes them with BatchWriteItem on Flush
+// Batches are limited to MaxBatchSize requests and UnprocessedItems are retried with backoff
+// A batch must not contain two requests for the same key, so only the last request queued for each key is written
+type BatchWriter struct {
+	batchConfig
+	client   dynamodbiface.DynamoDBAPI
+	keyNames keyNameCache
+	mu       sync.Mutex
+	pending  []tableWriteRequest
+}
+
+// BatchWriteResult - Outcome of a Flush, Unprocessed holds every request that could not be written
+// Requests replaced by a later one for the same key count as neither written nor failed
+type BatchWriteResult struct {
+	Written     int
+	Failed      int
+	Unprocessed map[string][]*db.WriteRequest
+}
+
+// BatchWriteError - Returned by Flush when some requests could not be written, Err is the last error seen if any
+type BatchWriteError struct {
+	Failed int
+	Err    error
+}
+
+func (e *BatchWriteError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("Error: %d write requests failed: %v", e.Failed, e.Err)
+	}
+	return fmt.Sprintf("Error: %d write requests were still unprocessed after retrying", e.Failed)
+}
+
+func (e *BatchWriteError) Unwrap() error {
+	return e.Err
+}
+
+// NewBatchWriter - Creates a BatchWriter using the given client
+func NewBatchWriter(client dynamodbiface.DynamoDBAPI, opts ...BatchOption) *BatchWriter {
+	return &BatchWriter{
+		batchConfig: newBatchConfig(opts),
+		client:      client,
+	}
+}
+
+func (w *BatchWriter) add(table string, request *db.WriteRequest) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(w.pending, tableWriteRequest{table: table, request: request})
+}
+
+// Put - Queues a put of the item into the table
+func (w *BatchWriter) Put(tableName string, item map[string]*db.AttributeValue) {
+	w.add(tableName, &db.WriteRequest{PutRequest: &db.PutRequest{Item: item}})
+}
+
+// Delete - Queues a delete of the key from the table
+func (w *BatchWriter) Delete(tableName string, key map[string]*db.AttributeValue) {
+	w.add(tableName, &db.WriteRequest{DeleteRequest: &db.DeleteRequest{Key: key}})
+}
+
+// PutItem - Marshals the struct and queues a put into the table named by its TableOptions
+func (w *BatchWriter) PutItem(item interface{}) error {
+	av, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return err
+	}
+	table := TableNameFromStruct(item)
+	if key, err := KeyFromStruct(item); err == nil {
+		w.keyNames.set(table, sortedNames(key))
+	}
+	w.Put(table, av)
+	return nil
+}
+
+// PutItems - Queues a put for every struct in the slice, see PutItem
+func (w *BatchWriter) PutItems(items interface{}) error {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("Error: Input argument must be a slice")
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := w.PutItem(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Len - Returns the number of queued requests
+func (w *BatchWriter) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+// Flush - Writes every queued request, the queue is emptied even if some requests fail
+func (w *BatchWriter) Flush(ctx context.Context) (*BatchWriteResult, error) {
+	w.mu.Lock()
+	pending := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	result := &BatchWriteResult{Unprocessed: map[string][]*db.WriteRequest{}}
+	pending, failed, lastErr := w.dedupe(ctx, pending)
+	for _, r := range failed {
+		result.Failed++
+		result.Unprocessed[r.table] = append(result.Unprocessed[r.table], r.request)
+	}
+
+	batches := make(chan []tableWriteRequest)
+	go func() {
+		defer close(batches)
+		for i := 0; i < len(pending); i += MaxBatchSize {
+			j := i + MaxBatchSize
+			if j > len(pending) {
+				j = len(pending)
+			}
+			// once ctx is done writeBatch returns immediately and the batch counts as failed
+			batches <- pending[i:j]
+		}
+	}()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				written, unprocessed, err := w.writeBatch(ctx, batch)
+				mu.Lock()
+				result.Written += written
+				for _, r := range unprocessed {
+					result.Failed++
+					result.Unprocessed[r.table] = append(result.Unprocessed[r.table], r.request)
+				}
+				if err != nil {
+					lastErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if result.Failed > 0 {
+		return result, &BatchWriteError{Failed: result.Failed, Err: lastErr}
+	}
+	return result, nil
+}
+
+// dedupe - Keeps the last request queued for each key, in the position of the first one
+// Requests for tables whose key schema cannot be read are returned as failed
+func (w *BatchWriter) dedupe(ctx context.Context, pending []tableWriteRequest) ([]tableWriteRequest, []tableWriteRequest, error) {
+	unique := []tableWriteRequest{}
+	failed := []tableWriteRequest{}
+	var lastErr error
+	positions := map[string]int{}
+	tableErrs := map[string]error{}
+	for _, r := range pending {
+		if tableErrs[r.table] != nil {
+			failed = append(failed, r)
+			continue
+		}
+		names, err := w.keyNames.get(ctx, w.client, r.table)
+		if err != nil {
+			tableErrs[r.table] = err
+			lastErr = err
+			failed = append(failed, r)
+			continue
+		}
+		var key map[string]*db.AttributeValue
+		if r.request.PutRequest != nil {
+			key = r.request.PutRequest.Item
+		} else if r.request.DeleteRequest != nil {
+			key = r.request.DeleteRequest.Key
+		}
+		sig := r.table + "|" + keySignature(names, key)
+		if i, ok := positions[sig]; ok {
+			unique[i] = r
+			continue
+		}
+		positions[sig] = len(unique)
+		unique = append(unique, r)
+	}
+	return unique, failed, lastErr
+}
+
+// writeBatch - Writes a single batch, retrying unprocessed items and throttling errors until maxRetries is reached
+func (w *BatchWriter) writeBatch(ctx context.Context, batch []tableWriteRequest) (int, []tableWriteRequest, error) {
+	total := len(batch)
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return total - len(batch), batch, err
+		}
+		requestItems := map[string][]*db.WriteRequest{}
+		for _, r := range batch {
+			requestItems[r.table] = append(requestItems[r.table], r.request)
+		}
+		out, err := w.client.BatchWriteItemWithContext(ctx, &db.BatchWriteItemInput{RequestItems: requestItems})
+		if err != nil && !isThrottlingError(err) {
+			return total - len(batch), batch, err
+		}
+		if err == nil {
+			batch = batch[:0:0]
+			for table, requests := range out.UnprocessedItems {
+				for _, r := range requests {
+					batch = append(batch, tableWriteRequest{table: table, request: r})
+				}
+			}
+			if len(batch) == 0 {
+				return total, nil, nil
+			}
+		}
+		if attempt >= w.maxRetries {
+			return total - len(batch), batch, err
+		}
+		if err := sleep(ctx, w.backoff(attempt)); err != nil {
+			return total - len(batch), batch, err
+		}
+	}
+}
+
+// backoff - Exponential backoff with equal jitter
+func (c batchConfig) backoff(attempt int) time.Duration {
+	backoff := c.maxBackoff
+	if attempt < 32 {
+		if b := c.initialBackoff << uint(attempt); b > 0 && b < c.maxBackoff {
+			backoff = b
+		}
+	}
+	half := backoff / 2
+	if half <= 0 {
+		return backoff
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func isThrottlingError(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	switch aerr.Code() {
+	case db.ErrCodeProvisionedThroughputExceededException, db.ErrCodeRequestLimitExceeded, "ThrottlingException":
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
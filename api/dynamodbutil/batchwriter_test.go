@@ -0,0 +1,242 @@
+package dynamodbutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil/dynamodbtest"
+)
+
+// Price - Test model with a string HASH key and a numeric RANGE key
+type Price struct {
+	Symbol string `at:"S" kt:"HASH"`
+	Day    int64  `at:"N" kt:"RANGE"`
+	Close  float64
+}
+
+const pricesTable = "Price"
+
+func newPrices(t *testing.T) *dynamodbtest.DB {
+	t.Helper()
+	d, err := dynamodbtest.NewFromModels(Price{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func prices(symbol string, days int) []*Price {
+	out := make([]*Price, days)
+	for i := range out {
+		out[i] = &Price{Symbol: symbol, Day: int64(i + 1), Close: float64(i + 1)}
+	}
+	return out
+}
+
+func priceKey(symbol string, day string) map[string]*db.AttributeValue {
+	return map[string]*db.AttributeValue{
+		"Symbol": {S: aws.String(symbol)},
+		"Day":    {N: aws.String(day)},
+	}
+}
+
+// fastRetries - Keeps retries from slowing the tests down
+func fastRetries(opts ...dynamodbutil.BatchOption) []dynamodbutil.BatchOption {
+	return append([]dynamodbutil.BatchOption{dynamodbutil.WithBatchBackoff(time.Millisecond, time.Millisecond)}, opts...)
+}
+
+func flushPrices(t *testing.T, d *dynamodbtest.DB, ctx context.Context, items []*Price, opts ...dynamodbutil.BatchOption) (*dynamodbutil.BatchWriteResult, error) {
+	t.Helper()
+	w := dynamodbutil.NewBatchWriter(d, fastRetries(opts...)...)
+	if err := w.PutItems(items); err != nil {
+		t.Fatal(err)
+	}
+	return w.Flush(ctx)
+}
+
+func TestBatchWriterChunks(t *testing.T) {
+	d := newPrices(t)
+	res, err := flushPrices(t, d, context.Background(), prices("AAPL", 60), dynamodbutil.WithConcurrency(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Written != 60 || res.Failed != 0 {
+		t.Fatalf("got %d written and %d failed, want 60 and 0", res.Written, res.Failed)
+	}
+	if calls := d.Calls(dynamodbtest.OpBatchWriteItem); calls != 3 {
+		t.Fatalf("got %d BatchWriteItem calls, want 3 batches of at most %d", calls, dynamodbutil.MaxBatchSize)
+	}
+	if n := len(d.Items(pricesTable)); n != 60 {
+		t.Fatalf("got %d stored items, want 60", n)
+	}
+	// the key names come from the struct tags, so the table is never described
+	if calls := d.Calls(dynamodbtest.OpDescribeTable); calls != 0 {
+		t.Fatalf("got %d DescribeTable calls, want 0", calls)
+	}
+}
+
+func TestBatchWriterRetriesUnprocessedItems(t *testing.T) {
+	d := newPrices(t)
+	d.Inject(dynamodbtest.OpBatchWriteItem, dynamodbtest.Fault{Unprocessed: 10, Times: 2})
+	res, err := flushPrices(t, d, context.Background(), prices("AAPL", 30), dynamodbutil.WithConcurrency(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Written != 30 || res.Failed != 0 || len(res.Unprocessed) != 0 {
+		t.Fatalf("got %+v, want every item written", res)
+	}
+	if n := len(d.Items(pricesTable)); n != 30 {
+		t.Fatalf("got %d stored items, want 30", n)
+	}
+	// 2 batches, each unprocessed remainder is sent again
+	if calls := d.Calls(dynamodbtest.OpBatchWriteItem); calls != 4 {
+		t.Fatalf("got %d BatchWriteItem calls, want 4", calls)
+	}
+}
+
+func TestBatchWriterRetriesThrottling(t *testing.T) {
+	d := newPrices(t)
+	throttle := dynamodbtest.ThrottleFault()
+	throttle.Times = 3
+	d.Inject(dynamodbtest.OpBatchWriteItem, throttle)
+	res, err := flushPrices(t, d, context.Background(), prices("AAPL", 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Written != 10 {
+		t.Fatalf("got %d written, want 10", res.Written)
+	}
+	if calls := d.Calls(dynamodbtest.OpBatchWriteItem); calls != 4 {
+		t.Fatalf("got %d BatchWriteItem calls, want 3 throttled and 1 successful", calls)
+	}
+}
+
+func TestBatchWriterMaxRetries(t *testing.T) {
+	t.Run("unprocessed", func(t *testing.T) {
+		d := newPrices(t)
+		d.Inject(dynamodbtest.OpBatchWriteItem, dynamodbtest.UnprocessedFault(4))
+		res, err := flushPrices(t, d, context.Background(), prices("AAPL", 10), dynamodbutil.WithMaxRetries(2))
+		var bwErr *dynamodbutil.BatchWriteError
+		if !errors.As(err, &bwErr) || bwErr.Failed != 4 || bwErr.Err != nil {
+			t.Fatalf("got error %v, want a BatchWriteError for 4 unprocessed requests", err)
+		}
+		if res.Written != 6 || res.Failed != 4 || len(res.Unprocessed[pricesTable]) != 4 {
+			t.Fatalf("got %d written, %d failed and %d unprocessed, want 6, 4 and 4", res.Written, res.Failed, len(res.Unprocessed[pricesTable]))
+		}
+		if calls := d.Calls(dynamodbtest.OpBatchWriteItem); calls != 3 {
+			t.Fatalf("got %d BatchWriteItem calls, want the first and 2 retries", calls)
+		}
+	})
+	t.Run("throttled", func(t *testing.T) {
+		d := newPrices(t)
+		d.Inject(dynamodbtest.OpBatchWriteItem, dynamodbtest.ThrottleFault())
+		res, err := flushPrices(t, d, context.Background(), prices("AAPL", 30), dynamodbutil.WithMaxRetries(1), dynamodbutil.WithConcurrency(1))
+		var aerr awserr.Error
+		if !errors.As(err, &aerr) || aerr.Code() != db.ErrCodeProvisionedThroughputExceededException {
+			t.Fatalf("got error %v, want the throttling error", err)
+		}
+		if res.Written != 0 || res.Failed != 30 {
+			t.Fatalf("got %d written and %d failed, want 0 and 30", res.Written, res.Failed)
+		}
+		if calls := d.Calls(dynamodbtest.OpBatchWriteItem); calls != 4 {
+			t.Fatalf("got %d BatchWriteItem calls, want 2 per batch", calls)
+		}
+	})
+}
+
+func TestBatchWriterCancellation(t *testing.T) {
+	t.Run("before flushing", func(t *testing.T) {
+		d := newPrices(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		res, err := flushPrices(t, d, ctx, prices("AAPL", 30))
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got error %v, want context.Canceled", err)
+		}
+		if res.Failed != 30 || d.Calls(dynamodbtest.OpBatchWriteItem) != 0 {
+			t.Fatalf("got %d failed after %d calls, want 30 and no calls", res.Failed, d.Calls(dynamodbtest.OpBatchWriteItem))
+		}
+	})
+	t.Run("while backing off", func(t *testing.T) {
+		d := newPrices(t)
+		d.Inject(dynamodbtest.OpBatchWriteItem, dynamodbtest.ThrottleFault())
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		w := dynamodbutil.NewBatchWriter(d, dynamodbutil.WithBatchBackoff(time.Hour, time.Hour))
+		if err := w.PutItems(prices("AAPL", 5)); err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		res, err := w.Flush(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("got error %v, want context.DeadlineExceeded", err)
+		}
+		if res.Failed != 5 || time.Since(start) > time.Second {
+			t.Fatalf("got %d failed after %v, want 5 failed as soon as the deadline passed", res.Failed, time.Since(start))
+		}
+	})
+}
+
+func TestBatchWriterKeepsLastRequestPerKey(t *testing.T) {
+	d := newPrices(t)
+	w := dynamodbutil.NewBatchWriter(d, fastRetries()...)
+	for _, p := range []*Price{
+		{Symbol: "AAPL", Day: 1, Close: 1},
+		{Symbol: "AAPL", Day: 2, Close: 2},
+		{Symbol: "AAPL", Day: 1, Close: 3},
+		{Symbol: "MSFT", Day: 1, Close: 4},
+	} {
+		if err := w.PutItem(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a delete after a put of the same key wins
+	w.Delete(pricesTable, priceKey("MSFT", "1"))
+	res, err := w.Flush(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Written != 3 || res.Failed != 0 {
+		t.Fatalf("got %d written and %d failed, want the 3 distinct keys written", res.Written, res.Failed)
+	}
+	got := []string{}
+	for _, item := range d.Items(pricesTable) {
+		got = append(got, fmt.Sprintf("%s/%s=%s", *item["Symbol"].S, *item["Day"].N, *item["Close"].N))
+	}
+	if fmt.Sprint(got) != "[AAPL/1=3 AAPL/2=2]" {
+		t.Fatalf("got %v, want [AAPL/1=3 AAPL/2=2]", got)
+	}
+}
+
+func TestBatchWriterDescribesTablesOfRawRequests(t *testing.T) {
+	d := newPrices(t)
+	w := dynamodbutil.NewBatchWriter(d, fastRetries()...)
+	for _, close := range []string{"1", "2"} {
+		item := priceKey("AAPL", "1")
+		item["Close"] = &db.AttributeValue{N: aws.String(close)}
+		w.Put(pricesTable, item)
+	}
+	w.Put("Missing", priceKey("AAPL", "1"))
+	res, err := w.Flush(context.Background())
+	var bwErr *dynamodbutil.BatchWriteError
+	var aerr awserr.Error
+	if !errors.As(err, &bwErr) || !errors.As(err, &aerr) || aerr.Code() != db.ErrCodeResourceNotFoundException {
+		t.Fatalf("got error %v, want a BatchWriteError for the missing table", err)
+	}
+	if res.Written != 1 || res.Failed != 1 || len(res.Unprocessed["Missing"]) != 1 {
+		t.Fatalf("got %+v, want 1 written and the request for the missing table failed", res)
+	}
+	if calls := d.Calls(dynamodbtest.OpDescribeTable); calls != 2 {
+		t.Fatalf("got %d DescribeTable calls, want 1 per table", calls)
+	}
+	if items := d.Items(pricesTable); len(items) != 1 || *items[0]["Close"].N != "2" {
+		t.Fatalf("got %v, want only the last put", items)
+	}
+}
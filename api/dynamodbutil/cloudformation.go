@@ -0,0 +1,96 @@
+package dynamodbutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// WriteCloudFormation - Writes the definitions as the `Resources` section of a CloudFormation template, in the layout of serverless/services/dynamodb/serverless.yml
+func WriteCloudFormation(w io.Writer, defs ...*TableDefinition) error {
+	bw := bufio.NewWriter(w)
+	line := func(indent int, format string, args ...interface{}) {
+		for i := 0; i < indent; i++ {
+			bw.WriteString("  ")
+		}
+		fmt.Fprintf(bw, format+"\n", args...)
+	}
+	keySchema := func(indent int, schema []*db.KeySchemaElement) {
+		line(indent, "KeySchema:")
+		for _, k := range schema {
+			line(indent+1, "- AttributeName: %s", aws.StringValue(k.AttributeName))
+			line(indent+1, "  KeyType: %s", aws.StringValue(k.KeyType))
+		}
+	}
+	projection := func(indent int, p *db.Projection) {
+		line(indent, "Projection:")
+		line(indent+1, "ProjectionType: %s", aws.StringValue(p.ProjectionType))
+		if len(p.NonKeyAttributes) > 0 {
+			line(indent+1, "NonKeyAttributes:")
+			for _, a := range p.NonKeyAttributes {
+				line(indent+2, "- %s", aws.StringValue(a))
+			}
+		}
+	}
+	throughput := func(indent int, t *db.ProvisionedThroughput) {
+		if t == nil {
+			return
+		}
+		line(indent, "ProvisionedThroughput:")
+		line(indent+1, "ReadCapacityUnits: %d", aws.Int64Value(t.ReadCapacityUnits))
+		line(indent+1, "WriteCapacityUnits: %d", aws.Int64Value(t.WriteCapacityUnits))
+	}
+
+	line(0, "Resources:")
+	for _, def := range defs {
+		in := def.CreateTableInput
+		line(1, "%s:", aws.StringValue(in.TableName))
+		line(2, "Type: \"AWS::DynamoDB::Table\"")
+		line(2, "Properties:")
+		line(3, "TableName: %s", aws.StringValue(in.TableName))
+		if aws.StringValue(in.BillingMode) == db.BillingModePayPerRequest {
+			line(3, "BillingMode: %s", db.BillingModePayPerRequest)
+		}
+		if in.StreamSpecification != nil {
+			line(3, "StreamSpecification:")
+			line(4, "StreamViewType: %s", aws.StringValue(in.StreamSpecification.StreamViewType))
+		}
+		line(3, "AttributeDefinitions:")
+		for _, a := range in.AttributeDefinitions {
+			line(4, "- AttributeName: %s", aws.StringValue(a.AttributeName))
+			line(4, "  AttributeType: %s", aws.StringValue(a.AttributeType))
+		}
+		keySchema(3, in.KeySchema)
+		if len(in.GlobalSecondaryIndexes) > 0 {
+			line(3, "GlobalSecondaryIndexes:")
+			for _, gsi := range in.GlobalSecondaryIndexes {
+				line(4, "- IndexName: %s", aws.StringValue(gsi.IndexName))
+				keySchema(5, gsi.KeySchema)
+				projection(5, gsi.Projection)
+				throughput(5, gsi.ProvisionedThroughput)
+			}
+		}
+		if len(in.LocalSecondaryIndexes) > 0 {
+			line(3, "LocalSecondaryIndexes:")
+			for _, lsi := range in.LocalSecondaryIndexes {
+				line(4, "- IndexName: %s", aws.StringValue(lsi.IndexName))
+				keySchema(5, lsi.KeySchema)
+				projection(5, lsi.Projection)
+			}
+		}
+		throughput(3, in.ProvisionedThroughput)
+		if def.UpdateTimeToLiveInput != nil {
+			line(3, "TimeToLiveSpecification:")
+			line(4, "AttributeName: %s", aws.StringValue(def.UpdateTimeToLiveInput.TimeToLiveSpecification.AttributeName))
+			line(4, "Enabled: true")
+		}
+		if def.UpdateContinuousBackupsInput != nil {
+			line(3, "PointInTimeRecoverySpecification:")
+			line(4, "PointInTimeRecoveryEnabled: true")
+		}
+	}
+	return bw.Flush()
+}
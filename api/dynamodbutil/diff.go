@@ -0,0 +1,183 @@
+package dynamodbutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// LiveTable - The deployed state of a table, Table is nil if it does not exist
+type LiveTable struct {
+	Table             *db.TableDescription
+	TimeToLive        *db.TimeToLiveDescription
+	ContinuousBackups *db.ContinuousBackupsDescription
+}
+
+// DescribeLiveTable - Describes the table along with its TTL and continuous backup settings
+func DescribeLiveTable(ddbClient dynamodbiface.DynamoDBAPI, tableName string) (*LiveTable, error) {
+	out, err := ddbClient.DescribeTable(&db.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == db.ErrCodeResourceNotFoundException {
+			return &LiveTable{}, nil
+		}
+		return nil, err
+	}
+	live := &LiveTable{Table: out.Table}
+	ttl, err := ddbClient.DescribeTimeToLive(&db.DescribeTimeToLiveInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return nil, err
+	}
+	live.TimeToLive = ttl.TimeToLiveDescription
+	backups, err := ddbClient.DescribeContinuousBackups(&db.DescribeContinuousBackupsInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return nil, err
+	}
+	live.ContinuousBackups = backups.ContinuousBackupsDescription
+	return live, nil
+}
+
+// DiffTable - Lists every difference between the definition and the live table, an empty result means they match
+func DiffTable(def *TableDefinition, live *LiveTable) []string {
+	want := def.CreateTableInput
+	if live == nil || live.Table == nil {
+		return []string{fmt.Sprintf("table %s does not exist", aws.StringValue(want.TableName))}
+	}
+	have := live.Table
+	diffs := []string{}
+	add := func(field string, want, have string) {
+		if want != have {
+			diffs = append(diffs, fmt.Sprintf("%s: want %s, have %s", field, orNone(want), orNone(have)))
+		}
+	}
+	add("AttributeDefinitions", formatAttributeDefinitions(want.AttributeDefinitions), formatAttributeDefinitions(have.AttributeDefinitions))
+	add("KeySchema", formatKeySchema(want.KeySchema), formatKeySchema(have.KeySchema))
+	haveBilling := db.BillingModeProvisioned
+	if have.BillingModeSummary != nil && have.BillingModeSummary.BillingMode != nil {
+		haveBilling = aws.StringValue(have.BillingModeSummary.BillingMode)
+	}
+	add("BillingMode", aws.StringValue(want.BillingMode), haveBilling)
+	if aws.StringValue(want.BillingMode) == db.BillingModeProvisioned {
+		add("ProvisionedThroughput", formatThroughput(want.ProvisionedThroughput), formatThroughputDescription(have.ProvisionedThroughput))
+	}
+	add("StreamViewType", formatStream(want.StreamSpecification), formatStream(have.StreamSpecification))
+
+	wantGSIs := map[string]string{}
+	for _, gsi := range want.GlobalSecondaryIndexes {
+		wantGSIs[aws.StringValue(gsi.IndexName)] = formatIndex(gsi.KeySchema, gsi.Projection)
+	}
+	haveGSIs := map[string]string{}
+	for _, gsi := range have.GlobalSecondaryIndexes {
+		haveGSIs[aws.StringValue(gsi.IndexName)] = formatIndex(gsi.KeySchema, gsi.Projection)
+	}
+	diffs = append(diffs, diffIndexes("GlobalSecondaryIndex", wantGSIs, haveGSIs)...)
+	wantLSIs := map[string]string{}
+	for _, lsi := range want.LocalSecondaryIndexes {
+		wantLSIs[aws.StringValue(lsi.IndexName)] = formatIndex(lsi.KeySchema, lsi.Projection)
+	}
+	haveLSIs := map[string]string{}
+	for _, lsi := range have.LocalSecondaryIndexes {
+		haveLSIs[aws.StringValue(lsi.IndexName)] = formatIndex(lsi.KeySchema, lsi.Projection)
+	}
+	diffs = append(diffs, diffIndexes("LocalSecondaryIndex", wantLSIs, haveLSIs)...)
+
+	wantTTL := ""
+	if def.UpdateTimeToLiveInput != nil {
+		wantTTL = aws.StringValue(def.UpdateTimeToLiveInput.TimeToLiveSpecification.AttributeName)
+	}
+	haveTTL := ""
+	if live.TimeToLive != nil && aws.StringValue(live.TimeToLive.TimeToLiveStatus) != db.TimeToLiveStatusDisabled {
+		haveTTL = aws.StringValue(live.TimeToLive.AttributeName)
+	}
+	add("TimeToLive", wantTTL, haveTTL)
+	wantPITR := def.UpdateContinuousBackupsInput != nil
+	havePITR := live.ContinuousBackups != nil && live.ContinuousBackups.PointInTimeRecoveryDescription != nil &&
+		aws.StringValue(live.ContinuousBackups.PointInTimeRecoveryDescription.PointInTimeRecoveryStatus) == db.PointInTimeRecoveryStatusEnabled
+	add("PointInTimeRecovery", fmt.Sprint(wantPITR), fmt.Sprint(havePITR))
+	return diffs
+}
+
+func diffIndexes(kind string, want, have map[string]string) []string {
+	names := map[string]bool{}
+	for name := range want {
+		names[name] = true
+	}
+	for name := range have {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	diffs := []string{}
+	for _, name := range sorted {
+		if want[name] != have[name] {
+			diffs = append(diffs, fmt.Sprintf("%s %s: want %s, have %s", kind, name, orNone(want[name]), orNone(have[name])))
+		}
+	}
+	return diffs
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// formatAttributeDefinitions - Attribute definitions are unordered so they are sorted by name
+func formatAttributeDefinitions(defs []*db.AttributeDefinition) string {
+	parts := make([]string, len(defs))
+	for i, d := range defs {
+		parts[i] = fmt.Sprintf("%s:%s", aws.StringValue(d.AttributeName), aws.StringValue(d.AttributeType))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func formatKeySchema(schema []*db.KeySchemaElement) string {
+	parts := make([]string, len(schema))
+	for i, k := range schema {
+		parts[i] = fmt.Sprintf("%s:%s", aws.StringValue(k.AttributeName), aws.StringValue(k.KeyType))
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatIndex(schema []*db.KeySchemaElement, projection *db.Projection) string {
+	s := formatKeySchema(schema)
+	if projection != nil {
+		s += " " + aws.StringValue(projection.ProjectionType)
+		if len(projection.NonKeyAttributes) > 0 {
+			nonKey := aws.StringValueSlice(projection.NonKeyAttributes)
+			sort.Strings(nonKey)
+			s += "(" + strings.Join(nonKey, ",") + ")"
+		}
+	}
+	return s
+}
+
+func formatThroughput(t *db.ProvisionedThroughput) string {
+	if t == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", aws.Int64Value(t.ReadCapacityUnits), aws.Int64Value(t.WriteCapacityUnits))
+}
+
+func formatThroughputDescription(t *db.ProvisionedThroughputDescription) string {
+	if t == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", aws.Int64Value(t.ReadCapacityUnits), aws.Int64Value(t.WriteCapacityUnits))
+}
+
+func formatStream(s *db.StreamSpecification) string {
+	if s == nil || !aws.BoolValue(s.StreamEnabled) {
+		return ""
+	}
+	return aws.StringValue(s.StreamViewType)
+}
@@ -0,0 +1,167 @@
+package dynamodbutil_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil/dynamodbtest"
+)
+
+// Diffed - Test model using every dimension compared by DiffTable
+type Diffed struct {
+	ID      string `at:"S" kt:"HASH"`
+	Date    string `at:"S" kt:"RANGE"`
+	Group   string `at:"S" gsi:"GroupIndex,HASH"`
+	Score   int64  `at:"N" lsi:"ScoreIndex,RANGE"`
+	Expires int64
+}
+
+func (Diffed) TableOptions() dynamodbutil.TableOptions {
+	return dynamodbutil.TableOptions{
+		StreamViewType:      db.StreamViewTypeNewImage,
+		TTLAttribute:        "Expires",
+		ReadCapacityUnits:   1,
+		WriteCapacityUnits:  1,
+		PointInTimeRecovery: true,
+	}
+}
+
+func TestDiffTable(t *testing.T) {
+	d, err := dynamodbtest.NewFromModels(Diffed{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	live, err := dynamodbutil.DescribeLiveTable(d, "Diffed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		change func(def *dynamodbutil.TableDefinition)
+		want   []string
+	}{
+		{
+			name:   "matching",
+			change: func(def *dynamodbutil.TableDefinition) {},
+		},
+		{
+			name: "key schema",
+			change: func(def *dynamodbutil.TableDefinition) {
+				def.CreateTableInput.KeySchema[1].AttributeName = aws.String("Group")
+			},
+			want: []string{"KeySchema: want ID:HASH,Group:RANGE, have ID:HASH,Date:RANGE"},
+		},
+		{
+			name: "attribute definitions",
+			change: func(def *dynamodbutil.TableDefinition) {
+				def.CreateTableInput.AttributeDefinitions[0].AttributeType = aws.String(db.ScalarAttributeTypeB)
+			},
+			want: []string{"AttributeDefinitions: want Date:S,Group:S,ID:B,Score:N, have Date:S,Group:S,ID:S,Score:N"},
+		},
+		{
+			name: "billing mode",
+			change: func(def *dynamodbutil.TableDefinition) {
+				def.CreateTableInput.BillingMode = aws.String(db.BillingModePayPerRequest)
+				def.CreateTableInput.ProvisionedThroughput = nil
+			},
+			want: []string{"BillingMode: want PAY_PER_REQUEST, have PROVISIONED"},
+		},
+		{
+			name: "throughput",
+			change: func(def *dynamodbutil.TableDefinition) {
+				def.CreateTableInput.ProvisionedThroughput = &db.ProvisionedThroughput{ReadCapacityUnits: aws.Int64(5), WriteCapacityUnits: aws.Int64(2)}
+			},
+			want: []string{"ProvisionedThroughput: want 5/2, have 1/1"},
+		},
+		{
+			name: "stream view type",
+			change: func(def *dynamodbutil.TableDefinition) {
+				def.CreateTableInput.StreamSpecification.StreamViewType = aws.String(db.StreamViewTypeNewAndOldImages)
+			},
+			want: []string{"StreamViewType: want NEW_AND_OLD_IMAGES, have NEW_IMAGE"},
+		},
+		{
+			name: "no stream",
+			change: func(def *dynamodbutil.TableDefinition) {
+				def.CreateTableInput.StreamSpecification = nil
+			},
+			want: []string{"StreamViewType: want none, have NEW_IMAGE"},
+		},
+		{
+			name: "global index projection and a missing global index",
+			change: func(def *dynamodbutil.TableDefinition) {
+				gsi := def.CreateTableInput.GlobalSecondaryIndexes[0]
+				gsi.Projection = &db.Projection{ProjectionType: aws.String(db.ProjectionTypeInclude), NonKeyAttributes: aws.StringSlice([]string{"Score", "Expires"})}
+				def.CreateTableInput.GlobalSecondaryIndexes = append(def.CreateTableInput.GlobalSecondaryIndexes, &db.GlobalSecondaryIndex{
+					IndexName:  aws.String("ScoreIndex"),
+					KeySchema:  []*db.KeySchemaElement{{AttributeName: aws.String("Score"), KeyType: aws.String(db.KeyTypeHash)}},
+					Projection: &db.Projection{ProjectionType: aws.String(db.ProjectionTypeKeysOnly)},
+				})
+			},
+			want: []string{
+				"GlobalSecondaryIndex GroupIndex: want Group:HASH INCLUDE(Expires,Score), have Group:HASH ALL",
+				"GlobalSecondaryIndex ScoreIndex: want Score:HASH KEYS_ONLY, have none",
+			},
+		},
+		{
+			name: "extra local index",
+			change: func(def *dynamodbutil.TableDefinition) {
+				def.CreateTableInput.LocalSecondaryIndexes = nil
+			},
+			want: []string{"LocalSecondaryIndex ScoreIndex: want none, have ID:HASH,Score:RANGE ALL"},
+		},
+		{
+			name: "time to live attribute",
+			change: func(def *dynamodbutil.TableDefinition) {
+				def.UpdateTimeToLiveInput.TimeToLiveSpecification.AttributeName = aws.String("ExpiresAt")
+			},
+			want: []string{"TimeToLive: want ExpiresAt, have Expires"},
+		},
+		{
+			name: "no time to live",
+			change: func(def *dynamodbutil.TableDefinition) {
+				def.UpdateTimeToLiveInput = nil
+			},
+			want: []string{"TimeToLive: want none, have Expires"},
+		},
+		{
+			name: "no point in time recovery",
+			change: func(def *dynamodbutil.TableDefinition) {
+				def.UpdateContinuousBackupsInput = nil
+			},
+			want: []string{"PointInTimeRecovery: want false, have true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := dynamodbutil.TableDefinitionFromStruct(Diffed{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.change(def)
+			got := dynamodbutil.DiffTable(def, live)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestDiffTableMissingTable(t *testing.T) {
+	d := dynamodbtest.New()
+	live, err := dynamodbutil.DescribeLiveTable(d, "Diffed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, err := dynamodbutil.TableDefinitionFromStruct(Diffed{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(dynamodbutil.DiffTable(def, live)); got != "[table Diffed does not exist]" {
+		t.Fatalf("got %s, want the table reported missing", got)
+	}
+}
@@ -0,0 +1,377 @@
+// Package dynamodbtest provides an in-memory DynamoDB implementing the parts of dynamodbiface.DynamoDBAPI used by this repository, so code built on the SDK can be tested offline
+// Methods that are not implemented panic when called
+package dynamodbtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+)
+
+// keySchema - Attribute names of a table or index key, rng is empty if there is no RANGE key
+type keySchema struct {
+	hash string
+	rng  string
+}
+
+func newKeySchema(elements []*db.KeySchemaElement) keySchema {
+	ks := keySchema{}
+	for _, k := range elements {
+		if aws.StringValue(k.KeyType) == db.KeyTypeHash {
+			ks.hash = aws.StringValue(k.AttributeName)
+		} else {
+			ks.rng = aws.StringValue(k.AttributeName)
+		}
+	}
+	return ks
+}
+
+func (ks keySchema) names() []string {
+	if ks.rng == "" {
+		return []string{ks.hash}
+	}
+	return []string{ks.hash, ks.rng}
+}
+
+type table struct {
+	description *db.TableDescription
+	key         keySchema
+	types       map[string]string
+	indexes     map[string]keySchema
+	items       map[string]map[string]*db.AttributeValue
+	ttl         *db.TimeToLiveDescription
+	pitr        bool
+}
+
+// DB - In-memory DynamoDB, safe for concurrent use
+type DB struct {
+	dynamodbiface.DynamoDBAPI
+
+	mu     sync.RWMutex
+	tables map[string]*table
+
+	faultsMu sync.Mutex
+	faults   map[string][]*Fault
+	calls    map[string]int
+}
+
+// New - Creates an empty DB, tables must be created before use
+func New() *DB {
+	return &DB{
+		tables: map[string]*table{},
+		faults: map[string][]*Fault{},
+		calls:  map[string]int{},
+	}
+}
+
+// NewFromModels - Creates a DB with a table for each model, see dynamodbutil.CreateTableFromStruct
+func NewFromModels(models ...interface{}) (*DB, error) {
+	d := New()
+	for _, m := range models {
+		if err := dynamodbutil.CreateTableFromStruct(d, m); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// Items - Returns a copy of every item in the table, ordered by key
+func (d *DB) Items(tableName string) []map[string]*db.AttributeValue {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, ok := d.tables[tableName]
+	if !ok {
+		return nil
+	}
+	items := t.sorted(t.key, nil)
+	for i, item := range items {
+		items[i] = copyItem(item)
+	}
+	return items
+}
+
+func validationError(message string) error {
+	return awserr.New("ValidationException", message, nil)
+}
+
+func conditionalCheckFailed() error {
+	return awserr.New(db.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil)
+}
+
+func resourceNotFound(tableName string) error {
+	return awserr.New(db.ErrCodeResourceNotFoundException, fmt.Sprintf("Requested resource not found: Table: %s not found", tableName), nil)
+}
+
+// begin - Counts the call and applies any injected fault, returning the fault so batch operations can honour Unprocessed
+func (d *DB) begin(ctx context.Context, op string) (*Fault, error) {
+	f := d.nextFault(op)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if f != nil && f.Delay > 0 {
+		t := time.NewTimer(f.Delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+	if f != nil && f.Err != nil {
+		return nil, f.Err
+	}
+	return f, nil
+}
+
+func (d *DB) table(name *string) (*table, error) {
+	t, ok := d.tables[aws.StringValue(name)]
+	if !ok {
+		return nil, resourceNotFound(aws.StringValue(name))
+	}
+	return t, nil
+}
+
+// CreateTable - Tables are ACTIVE as soon as they are created
+func (d *DB) CreateTable(input *db.CreateTableInput) (*db.CreateTableOutput, error) {
+	return d.CreateTableWithContext(context.Background(), input)
+}
+
+func (d *DB) CreateTableWithContext(ctx aws.Context, input *db.CreateTableInput, _ ...request.Option) (*db.CreateTableOutput, error) {
+	if _, err := d.begin(ctx, OpCreateTable); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, validationError(err.Error())
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	name := aws.StringValue(input.TableName)
+	if _, ok := d.tables[name]; ok {
+		return nil, awserr.New(db.ErrCodeResourceInUseException, fmt.Sprintf("Table already exists: %s", name), nil)
+	}
+	t := &table{
+		key:     newKeySchema(input.KeySchema),
+		types:   map[string]string{},
+		indexes: map[string]keySchema{},
+		items:   map[string]map[string]*db.AttributeValue{},
+	}
+	for _, a := range input.AttributeDefinitions {
+		t.types[aws.StringValue(a.AttributeName)] = aws.StringValue(a.AttributeType)
+	}
+	schemas := [][]*db.KeySchemaElement{input.KeySchema}
+	description := &db.TableDescription{
+		TableName:            input.TableName,
+		TableArn:             aws.String("arn:aws:dynamodb:local:000000000000:table/" + name),
+		TableStatus:          aws.String(db.TableStatusActive),
+		CreationDateTime:     aws.Time(time.Now()),
+		AttributeDefinitions: input.AttributeDefinitions,
+		KeySchema:            input.KeySchema,
+		StreamSpecification:  input.StreamSpecification,
+		ItemCount:            aws.Int64(0),
+	}
+	billing := aws.StringValue(input.BillingMode)
+	if billing == "" {
+		billing = db.BillingModeProvisioned
+	}
+	description.BillingModeSummary = &db.BillingModeSummary{BillingMode: aws.String(billing)}
+	if input.ProvisionedThroughput != nil {
+		description.ProvisionedThroughput = &db.ProvisionedThroughputDescription{
+			ReadCapacityUnits:  input.ProvisionedThroughput.ReadCapacityUnits,
+			WriteCapacityUnits: input.ProvisionedThroughput.WriteCapacityUnits,
+		}
+	}
+	for _, gsi := range input.GlobalSecondaryIndexes {
+		t.indexes[aws.StringValue(gsi.IndexName)] = newKeySchema(gsi.KeySchema)
+		schemas = append(schemas, gsi.KeySchema)
+		description.GlobalSecondaryIndexes = append(description.GlobalSecondaryIndexes, &db.GlobalSecondaryIndexDescription{
+			IndexName:   gsi.IndexName,
+			IndexStatus: aws.String(db.IndexStatusActive),
+			KeySchema:   gsi.KeySchema,
+			Projection:  gsi.Projection,
+		})
+	}
+	for _, lsi := range input.LocalSecondaryIndexes {
+		t.indexes[aws.StringValue(lsi.IndexName)] = newKeySchema(lsi.KeySchema)
+		schemas = append(schemas, lsi.KeySchema)
+		description.LocalSecondaryIndexes = append(description.LocalSecondaryIndexes, &db.LocalSecondaryIndexDescription{
+			IndexName:  lsi.IndexName,
+			KeySchema:  lsi.KeySchema,
+			Projection: lsi.Projection,
+		})
+	}
+	for _, schema := range schemas {
+		for _, k := range schema {
+			if _, ok := t.types[aws.StringValue(k.AttributeName)]; !ok {
+				return nil, validationError(fmt.Sprintf("Key attribute %s is missing from AttributeDefinitions", aws.StringValue(k.AttributeName)))
+			}
+		}
+	}
+	if input.StreamSpecification != nil && aws.BoolValue(input.StreamSpecification.StreamEnabled) {
+		description.LatestStreamArn = aws.String(aws.StringValue(description.TableArn) + "/stream/" + time.Now().UTC().Format("2006-01-02T15:04:05.000"))
+	}
+	t.description = description
+	d.tables[name] = t
+	return &db.CreateTableOutput{TableDescription: description}, nil
+}
+
+func (d *DB) DescribeTable(input *db.DescribeTableInput) (*db.DescribeTableOutput, error) {
+	return d.DescribeTableWithContext(context.Background(), input)
+}
+
+func (d *DB) DescribeTableWithContext(ctx aws.Context, input *db.DescribeTableInput, _ ...request.Option) (*db.DescribeTableOutput, error) {
+	if _, err := d.begin(ctx, OpDescribeTable); err != nil {
+		return nil, err
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, err := d.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	description := *t.description
+	description.ItemCount = aws.Int64(int64(len(t.items)))
+	return &db.DescribeTableOutput{Table: &description}, nil
+}
+
+func (d *DB) DeleteTable(input *db.DeleteTableInput) (*db.DeleteTableOutput, error) {
+	return d.DeleteTableWithContext(context.Background(), input)
+}
+
+func (d *DB) DeleteTableWithContext(ctx aws.Context, input *db.DeleteTableInput, _ ...request.Option) (*db.DeleteTableOutput, error) {
+	if _, err := d.begin(ctx, OpDeleteTable); err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, err := d.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	delete(d.tables, aws.StringValue(input.TableName))
+	return &db.DeleteTableOutput{TableDescription: t.description}, nil
+}
+
+func (d *DB) ListTables(input *db.ListTablesInput) (*db.ListTablesOutput, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	names := []string{}
+	for name := range d.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &db.ListTablesOutput{TableNames: aws.StringSlice(names)}, nil
+}
+
+// WaitUntilTableExists - Tables are created ACTIVE, so this only checks the table exists
+func (d *DB) WaitUntilTableExists(input *db.DescribeTableInput) error {
+	return d.WaitUntilTableExistsWithContext(context.Background(), input)
+}
+
+func (d *DB) WaitUntilTableExistsWithContext(ctx aws.Context, input *db.DescribeTableInput, _ ...request.WaiterOption) error {
+	_, err := d.DescribeTableWithContext(ctx, input)
+	return err
+}
+
+func (d *DB) UpdateTimeToLive(input *db.UpdateTimeToLiveInput) (*db.UpdateTimeToLiveOutput, error) {
+	return d.UpdateTimeToLiveWithContext(context.Background(), input)
+}
+
+// UpdateTimeToLiveWithContext - Only records the setting, expired items are not deleted
+func (d *DB) UpdateTimeToLiveWithContext(ctx aws.Context, input *db.UpdateTimeToLiveInput, _ ...request.Option) (*db.UpdateTimeToLiveOutput, error) {
+	if _, err := d.begin(ctx, OpUpdateTimeToLive); err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, err := d.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	status := db.TimeToLiveStatusDisabled
+	if aws.BoolValue(input.TimeToLiveSpecification.Enabled) {
+		status = db.TimeToLiveStatusEnabled
+	}
+	t.ttl = &db.TimeToLiveDescription{
+		AttributeName:    input.TimeToLiveSpecification.AttributeName,
+		TimeToLiveStatus: aws.String(status),
+	}
+	return &db.UpdateTimeToLiveOutput{TimeToLiveSpecification: input.TimeToLiveSpecification}, nil
+}
+
+func (d *DB) DescribeTimeToLive(input *db.DescribeTimeToLiveInput) (*db.DescribeTimeToLiveOutput, error) {
+	return d.DescribeTimeToLiveWithContext(context.Background(), input)
+}
+
+func (d *DB) DescribeTimeToLiveWithContext(ctx aws.Context, input *db.DescribeTimeToLiveInput, _ ...request.Option) (*db.DescribeTimeToLiveOutput, error) {
+	if _, err := d.begin(ctx, OpDescribeTimeToLive); err != nil {
+		return nil, err
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, err := d.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	ttl := t.ttl
+	if ttl == nil {
+		ttl = &db.TimeToLiveDescription{TimeToLiveStatus: aws.String(db.TimeToLiveStatusDisabled)}
+	}
+	return &db.DescribeTimeToLiveOutput{TimeToLiveDescription: ttl}, nil
+}
+
+func (d *DB) UpdateContinuousBackups(input *db.UpdateContinuousBackupsInput) (*db.UpdateContinuousBackupsOutput, error) {
+	return d.UpdateContinuousBackupsWithContext(context.Background(), input)
+}
+
+func (d *DB) UpdateContinuousBackupsWithContext(ctx aws.Context, input *db.UpdateContinuousBackupsInput, _ ...request.Option) (*db.UpdateContinuousBackupsOutput, error) {
+	if _, err := d.begin(ctx, OpUpdateContinuousBackups); err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, err := d.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	t.pitr = aws.BoolValue(input.PointInTimeRecoverySpecification.PointInTimeRecoveryEnabled)
+	return &db.UpdateContinuousBackupsOutput{ContinuousBackupsDescription: t.continuousBackups()}, nil
+}
+
+func (d *DB) DescribeContinuousBackups(input *db.DescribeContinuousBackupsInput) (*db.DescribeContinuousBackupsOutput, error) {
+	return d.DescribeContinuousBackupsWithContext(context.Background(), input)
+}
+
+func (d *DB) DescribeContinuousBackupsWithContext(ctx aws.Context, input *db.DescribeContinuousBackupsInput, _ ...request.Option) (*db.DescribeContinuousBackupsOutput, error) {
+	if _, err := d.begin(ctx, OpDescribeContinuousBackups); err != nil {
+		return nil, err
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, err := d.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	return &db.DescribeContinuousBackupsOutput{ContinuousBackupsDescription: t.continuousBackups()}, nil
+}
+
+func (t *table) continuousBackups() *db.ContinuousBackupsDescription {
+	status := db.PointInTimeRecoveryStatusDisabled
+	if t.pitr {
+		status = db.PointInTimeRecoveryStatusEnabled
+	}
+	return &db.ContinuousBackupsDescription{
+		ContinuousBackupsStatus:        aws.String(db.ContinuousBackupsStatusEnabled),
+		PointInTimeRecoveryDescription: &db.PointInTimeRecoveryDescription{PointInTimeRecoveryStatus: aws.String(status)},
+	}
+}
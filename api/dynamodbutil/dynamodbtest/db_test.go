@@ -0,0 +1,362 @@
+package dynamodbtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Reading - Test model with a string HASH key and a string RANGE key
+type Reading struct {
+	Sensor string `at:"S" kt:"HASH"`
+	Date   string `at:"S" kt:"RANGE"`
+	Value  float64
+}
+
+const readingsTable = "Reading"
+
+func newReadings(t *testing.T, days int) *DB {
+	t.Helper()
+	d, err := NewFromModels(Reading{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sensor := range []string{"a", "b"} {
+		for i := 1; i <= days; i++ {
+			if _, err := d.PutItem(&db.PutItemInput{TableName: aws.String(readingsTable), Item: reading(sensor, i)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return d
+}
+
+func reading(sensor string, day int) map[string]*db.AttributeValue {
+	return map[string]*db.AttributeValue{
+		"Sensor": {S: aws.String(sensor)},
+		"Date":   {S: aws.String(date(day))},
+		"Value":  {N: aws.String(fmt.Sprint(day))},
+	}
+}
+
+func readingKey(sensor string, day int) map[string]*db.AttributeValue {
+	return map[string]*db.AttributeValue{
+		"Sensor": {S: aws.String(sensor)},
+		"Date":   {S: aws.String(date(day))},
+	}
+}
+
+// date - Days 1 to 31 fall in January 2020, later days in February
+func date(day int) string {
+	if day > 31 {
+		return fmt.Sprintf("2020-02-%02d", day-31)
+	}
+	return fmt.Sprintf("2020-01-%02d", day)
+}
+
+func errorCode(err error) string {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code()
+	}
+	return ""
+}
+
+func dates(items []map[string]*db.AttributeValue) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = aws.StringValue(item["Date"].S)
+	}
+	return out
+}
+
+func assertDates(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got dates %v, want %v", got, want)
+	}
+}
+
+func TestGetPutDeleteItem(t *testing.T) {
+	d := newReadings(t, 2)
+	table := aws.String(readingsTable)
+
+	out, err := d.GetItem(&db.GetItemInput{TableName: table, Key: readingKey("a", 2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.StringValue(out.Item["Value"].N) != "2" {
+		t.Fatalf("got item %v, want value 2", out.Item)
+	}
+	// items returned are copies
+	out.Item["Value"].N = aws.String("100")
+	if again, _ := d.GetItem(&db.GetItemInput{TableName: table, Key: readingKey("a", 2)}); aws.StringValue(again.Item["Value"].N) != "2" {
+		t.Fatal("modifying a returned item changed the stored item")
+	}
+
+	projected, err := d.GetItem(&db.GetItemInput{
+		TableName:                table,
+		Key:                      readingKey("a", 2),
+		ProjectionExpression:     aws.String("#v"),
+		ExpressionAttributeNames: map[string]*string{"#v": aws.String("Value")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projected.Item) != 1 || projected.Item["Value"] == nil {
+		t.Fatalf("got item %v, want only Value", projected.Item)
+	}
+
+	_, err = d.PutItem(&db.PutItemInput{TableName: table, Item: reading("a", 2), ConditionExpression: aws.String("attribute_not_exists(Sensor)")})
+	if errorCode(err) != db.ErrCodeConditionalCheckFailedException {
+		t.Fatalf("got error %v, want ConditionalCheckFailedException", err)
+	}
+	item := reading("a", 2)
+	item["Value"] = &db.AttributeValue{N: aws.String("20")}
+	put, err := d.PutItem(&db.PutItemInput{TableName: table, Item: item, ReturnValues: aws.String(db.ReturnValueAllOld)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.StringValue(put.Attributes["Value"].N) != "2" {
+		t.Fatalf("got old item %v, want value 2", put.Attributes)
+	}
+
+	if _, err := d.DeleteItem(&db.DeleteItemInput{TableName: table, Key: readingKey("a", 2)}); err != nil {
+		t.Fatal(err)
+	}
+	out, err = d.GetItem(&db.GetItemInput{TableName: table, Key: readingKey("a", 2)})
+	if err != nil || out.Item != nil {
+		t.Fatalf("got item %v and error %v, want no item", out.Item, err)
+	}
+	if n := len(d.Items(readingsTable)); n != 3 {
+		t.Fatalf("got %d items, want 3", n)
+	}
+
+	_, err = d.GetItem(&db.GetItemInput{TableName: table, Key: map[string]*db.AttributeValue{"Sensor": {S: aws.String("a")}}})
+	if errorCode(err) != "ValidationException" {
+		t.Fatalf("got error %v, want ValidationException for an incomplete key", err)
+	}
+	_, err = d.GetItem(&db.GetItemInput{TableName: aws.String("Missing"), Key: readingKey("a", 1)})
+	if errorCode(err) != db.ErrCodeResourceNotFoundException {
+		t.Fatalf("got error %v, want ResourceNotFoundException", err)
+	}
+}
+
+func TestQueryKeyConditions(t *testing.T) {
+	d := newReadings(t, 40)
+	query := func(condition string, values map[string]*db.AttributeValue, forward bool) []string {
+		t.Helper()
+		values[":s"] = &db.AttributeValue{S: aws.String("a")}
+		out, err := d.Query(&db.QueryInput{
+			TableName:                 aws.String(readingsTable),
+			KeyConditionExpression:    aws.String(condition),
+			ExpressionAttributeNames:  map[string]*string{"#d": aws.String("Date")},
+			ExpressionAttributeValues: values,
+			ScanIndexForward:          aws.Bool(forward),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dates(out.Items)
+	}
+	s := func(v string) *db.AttributeValue {
+		return &db.AttributeValue{S: aws.String(v)}
+	}
+
+	assertDates(t, query("Sensor = :s AND #d = :d", map[string]*db.AttributeValue{":d": s(date(3))}, true), date(3))
+	assertDates(t, query("Sensor = :s AND #d BETWEEN :a AND :b", map[string]*db.AttributeValue{":a": s(date(30)), ":b": s(date(33))}, true),
+		date(30), date(31), date(32), date(33))
+	assertDates(t, query("Sensor = :s and begins_with(#d, :p)", map[string]*db.AttributeValue{":p": s("2020-02")}, true),
+		date(32), date(33), date(34), date(35), date(36), date(37), date(38), date(39), date(40))
+	assertDates(t, query("Sensor = :s AND #d < :d", map[string]*db.AttributeValue{":d": s(date(3))}, false), date(2), date(1))
+	assertDates(t, query("Sensor = :s AND #d >= :d", map[string]*db.AttributeValue{":d": s(date(38))}, false), date(40), date(39), date(38))
+
+	_, err := d.Query(&db.QueryInput{
+		TableName:                 aws.String(readingsTable),
+		KeyConditionExpression:    aws.String("#d = :d"),
+		ExpressionAttributeNames:  map[string]*string{"#d": aws.String("Date")},
+		ExpressionAttributeValues: map[string]*db.AttributeValue{":d": s(date(3))},
+	})
+	if errorCode(err) != "ValidationException" {
+		t.Fatalf("got error %v, want ValidationException without a HASH key condition", err)
+	}
+}
+
+func TestQueryPagesReverse(t *testing.T) {
+	d := newReadings(t, 10)
+	got := []string{}
+	pages := 0
+	err := d.QueryPages(&db.QueryInput{
+		TableName:                 aws.String(readingsTable),
+		KeyConditionExpression:    aws.String("Sensor = :s"),
+		ExpressionAttributeValues: map[string]*db.AttributeValue{":s": {S: aws.String("b")}},
+		ScanIndexForward:          aws.Bool(false),
+		Limit:                     aws.Int64(3),
+	}, func(page *db.QueryOutput, _ bool) bool {
+		pages++
+		got = append(got, dates(page.Items)...)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDates(t, got, date(10), date(9), date(8), date(7), date(6), date(5), date(4), date(3), date(2), date(1))
+	if pages != 4 {
+		t.Fatalf("got %d pages, want 4", pages)
+	}
+}
+
+func TestScanPagination(t *testing.T) {
+	d := newReadings(t, 10)
+	seen := map[string]bool{}
+	pages := 0
+	err := d.ScanPages(&db.ScanInput{TableName: aws.String(readingsTable), Limit: aws.Int64(3)}, func(page *db.ScanOutput, last bool) bool {
+		pages++
+		if last != (page.LastEvaluatedKey == nil) {
+			t.Fatalf("page %d: last is %v with LastEvaluatedKey %v", pages, last, page.LastEvaluatedKey)
+		}
+		for _, item := range page.Items {
+			key := aws.StringValue(item["Sensor"].S) + aws.StringValue(item["Date"].S)
+			if seen[key] {
+				t.Fatalf("got %s twice", key)
+			}
+			seen[key] = true
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 20 || pages != 7 {
+		t.Fatalf("got %d items in %d pages, want 20 in 7", len(seen), pages)
+	}
+}
+
+func TestScanPagesDeletingStartKey(t *testing.T) {
+	d := newReadings(t, 10)
+	seen := map[string]bool{}
+	pages := 0
+	err := d.ScanPages(&db.ScanInput{TableName: aws.String(readingsTable), Limit: aws.Int64(4)}, func(page *db.ScanOutput, _ bool) bool {
+		pages++
+		for _, item := range page.Items {
+			key := aws.StringValue(item["Sensor"].S) + aws.StringValue(item["Date"].S)
+			if seen[key] {
+				t.Fatalf("got %s twice", key)
+			}
+			seen[key] = true
+		}
+		// the next page must continue after the deleted item rather than start over
+		if page.LastEvaluatedKey != nil {
+			if _, err := d.DeleteItem(&db.DeleteItemInput{TableName: aws.String(readingsTable), Key: page.LastEvaluatedKey}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return pages < 10
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 20 || pages != 5 {
+		t.Fatalf("got %d items in %d pages, want 20 in 5", len(seen), pages)
+	}
+}
+
+func writeRequests(sensor string, days int) []*db.WriteRequest {
+	requests := make([]*db.WriteRequest, days)
+	for i := range requests {
+		requests[i] = &db.WriteRequest{PutRequest: &db.PutRequest{Item: reading(sensor, i+1)}}
+	}
+	return requests
+}
+
+func TestBatchWriteItemUnprocessedFault(t *testing.T) {
+	d := newReadings(t, 0)
+	d.Inject(OpBatchWriteItem, Fault{Unprocessed: 2, Times: 1})
+	input := &db.BatchWriteItemInput{RequestItems: map[string][]*db.WriteRequest{readingsTable: writeRequests("a", 5)}}
+	out, err := d.BatchWriteItem(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(out.UnprocessedItems[readingsTable]); n != 2 {
+		t.Fatalf("got %d unprocessed items, want 2", n)
+	}
+	if n := len(d.Items(readingsTable)); n != 3 {
+		t.Fatalf("got %d items, want 3", n)
+	}
+	out, err = d.BatchWriteItem(&db.BatchWriteItemInput{RequestItems: out.UnprocessedItems})
+	if err != nil || len(out.UnprocessedItems) != 0 {
+		t.Fatalf("got %d unprocessed tables and error %v, want the fault used up", len(out.UnprocessedItems), err)
+	}
+	if n := len(d.Items(readingsTable)); n != 5 {
+		t.Fatalf("got %d items, want 5", n)
+	}
+
+	_, err = d.BatchWriteItem(&db.BatchWriteItemInput{RequestItems: map[string][]*db.WriteRequest{readingsTable: writeRequests("a", 26)}})
+	if errorCode(err) != "ValidationException" {
+		t.Fatalf("got error %v, want ValidationException for 26 requests", err)
+	}
+	duplicates := append(writeRequests("a", 1), writeRequests("a", 1)...)
+	_, err = d.BatchWriteItem(&db.BatchWriteItemInput{RequestItems: map[string][]*db.WriteRequest{readingsTable: duplicates}})
+	if errorCode(err) != "ValidationException" {
+		t.Fatalf("got error %v, want ValidationException for duplicate keys", err)
+	}
+}
+
+func TestBatchGetItemUnprocessedFault(t *testing.T) {
+	d := newReadings(t, 5)
+	keys := []map[string]*db.AttributeValue{}
+	for i := 1; i <= 5; i++ {
+		keys = append(keys, readingKey("a", i))
+	}
+	d.Inject(OpBatchGetItem, UnprocessedFault(3))
+	out, err := d.BatchGetItem(&db.BatchGetItemInput{RequestItems: map[string]*db.KeysAndAttributes{readingsTable: {Keys: keys}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Responses[readingsTable]) != 2 || len(out.UnprocessedKeys[readingsTable].Keys) != 3 {
+		t.Fatalf("got %d items and %d unprocessed keys, want 2 and 3", len(out.Responses[readingsTable]), len(out.UnprocessedKeys[readingsTable].Keys))
+	}
+	d.ClearFaults()
+	out, err = d.BatchGetItem(&db.BatchGetItemInput{RequestItems: out.UnprocessedKeys})
+	if err != nil || len(out.Responses[readingsTable]) != 3 {
+		t.Fatalf("got %d items and error %v, want the 3 remaining items", len(out.Responses[readingsTable]), err)
+	}
+}
+
+func TestThrottleFault(t *testing.T) {
+	d := newReadings(t, 1)
+	f := ThrottleFault()
+	f.Times = 2
+	d.Inject(AnyOperation, f)
+	get := func() error {
+		_, err := d.GetItemWithContext(context.Background(), &db.GetItemInput{TableName: aws.String(readingsTable), Key: readingKey("a", 1)})
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		if err := get(); errorCode(err) != db.ErrCodeProvisionedThroughputExceededException {
+			t.Fatalf("call %d: got error %v, want ProvisionedThroughputExceededException", i, err)
+		}
+	}
+	if err := get(); err != nil {
+		t.Fatal(err)
+	}
+	if n := d.Calls(OpGetItem); n != 3 {
+		t.Fatalf("got %d GetItem calls, want 3", n)
+	}
+}
+
+func TestSlowFaultCancelled(t *testing.T) {
+	d := newReadings(t, 1)
+	d.Inject(OpGetItem, SlowFault(time.Minute))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := d.GetItemWithContext(ctx, &db.GetItemInput{TableName: aws.String(readingsTable), Key: readingKey("a", 1)})
+	if errorCode(err) != request.CanceledErrorCode {
+		t.Fatalf("got error %v, want %s", err, request.CanceledErrorCode)
+	}
+}
@@ -0,0 +1,264 @@
+package dynamodbtest
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/aws"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// keyCondition - A single condition of a KeyConditionExpression
+type keyCondition struct {
+	name   string
+	op     string
+	values []*db.AttributeValue
+}
+
+func (c keyCondition) matches(av *db.AttributeValue) bool {
+	if av == nil {
+		return false
+	}
+	switch c.op {
+	case "begins_with":
+		if av.S != nil && c.values[0].S != nil {
+			return strings.HasPrefix(*av.S, *c.values[0].S)
+		}
+		if av.B != nil && c.values[0].B != nil {
+			return bytes.HasPrefix(av.B, c.values[0].B)
+		}
+		return false
+	case "BETWEEN":
+		lo, ok1 := compare(av, c.values[0])
+		hi, ok2 := compare(av, c.values[1])
+		return ok1 && ok2 && lo >= 0 && hi <= 0
+	}
+	cmp, ok := compare(av, c.values[0])
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// tokenize - Splits an expression into names, placeholders, operators and punctuation
+func tokenize(expr string) []string {
+	tokens := []string{}
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.ContainsRune("(),", c):
+			tokens = append(tokens, string(c))
+			i++
+		case strings.ContainsRune("<>=", c):
+			j := i + 1
+			if j < len(expr) && strings.ContainsRune("=>", rune(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		default:
+			j := i
+			for j < len(expr) && !unicode.IsSpace(rune(expr[j])) && !strings.ContainsRune("(),<>=", rune(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
+
+// expressionContext - Resolves #name and :value placeholders
+type expressionContext struct {
+	names  map[string]*string
+	values map[string]*db.AttributeValue
+}
+
+func (e expressionContext) name(token string) (string, error) {
+	if strings.HasPrefix(token, "#") {
+		n, ok := e.names[token]
+		if !ok {
+			return "", validationError(fmt.Sprintf("An expression attribute name used in the document path is not defined; attribute name: %s", token))
+		}
+		return aws.StringValue(n), nil
+	}
+	if token == "" || strings.HasPrefix(token, ":") {
+		return "", validationError(fmt.Sprintf("Invalid attribute name %q", token))
+	}
+	return token, nil
+}
+
+func (e expressionContext) value(token string) (*db.AttributeValue, error) {
+	v, ok := e.values[token]
+	if !ok || !strings.HasPrefix(token, ":") {
+		return nil, validationError(fmt.Sprintf("An expression attribute value used in expression is not defined; attribute value: %s", token))
+	}
+	return v, nil
+}
+
+// parseKeyCondition - Supports `a = :v`, `a <op> :v`, `a BETWEEN :lo AND :hi` and `begins_with(a, :p)` joined by AND
+func parseKeyCondition(expr string, e expressionContext) ([]keyCondition, error) {
+	tokens := tokenize(expr)
+	conditions := []keyCondition{}
+	next := func() string {
+		if len(tokens) == 0 {
+			return ""
+		}
+		t := tokens[0]
+		tokens = tokens[1:]
+		return t
+	}
+	invalid := func() error {
+		return validationError(fmt.Sprintf("Invalid KeyConditionExpression: %s", expr))
+	}
+	for {
+		t := next()
+		if strings.EqualFold(t, "begins_with") {
+			if next() != "(" {
+				return nil, invalid()
+			}
+			name, err := e.name(next())
+			if err != nil {
+				return nil, err
+			}
+			if next() != "," {
+				return nil, invalid()
+			}
+			v, err := e.value(next())
+			if err != nil {
+				return nil, err
+			}
+			if next() != ")" {
+				return nil, invalid()
+			}
+			conditions = append(conditions, keyCondition{name: name, op: "begins_with", values: []*db.AttributeValue{v}})
+		} else {
+			name, err := e.name(t)
+			if err != nil {
+				return nil, err
+			}
+			op := next()
+			switch {
+			case strings.EqualFold(op, "BETWEEN"):
+				lo, err := e.value(next())
+				if err != nil {
+					return nil, err
+				}
+				if !strings.EqualFold(next(), "AND") {
+					return nil, invalid()
+				}
+				hi, err := e.value(next())
+				if err != nil {
+					return nil, err
+				}
+				conditions = append(conditions, keyCondition{name: name, op: "BETWEEN", values: []*db.AttributeValue{lo, hi}})
+			case op == "=" || op == "<" || op == "<=" || op == ">" || op == ">=":
+				v, err := e.value(next())
+				if err != nil {
+					return nil, err
+				}
+				conditions = append(conditions, keyCondition{name: name, op: op, values: []*db.AttributeValue{v}})
+			default:
+				return nil, invalid()
+			}
+		}
+		t = next()
+		if t == "" {
+			return conditions, nil
+		}
+		if !strings.EqualFold(t, "AND") {
+			return nil, invalid()
+		}
+	}
+}
+
+// parseProjection - Supports a comma separated list of top level attribute names
+func parseProjection(expr *string, e expressionContext) ([]string, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	names := []string{}
+	for _, part := range strings.Split(*expr, ",") {
+		name, err := e.name(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if strings.ContainsAny(name, ".[") {
+			return nil, validationError(fmt.Sprintf("dynamodbtest: nested ProjectionExpression paths are not supported: %s", name))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// parseCondition - Supports `attribute_exists(a)` and `attribute_not_exists(a)`, the conditions put and delete calls typically use
+func parseCondition(expr *string, e expressionContext) (func(item map[string]*db.AttributeValue) bool, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	tokens := tokenize(*expr)
+	if len(tokens) != 4 || tokens[1] != "(" || tokens[3] != ")" {
+		return nil, validationError(fmt.Sprintf("dynamodbtest: unsupported ConditionExpression: %s", *expr))
+	}
+	name, err := e.name(tokens[2])
+	if err != nil {
+		return nil, err
+	}
+	switch tokens[0] {
+	case "attribute_exists":
+		return func(item map[string]*db.AttributeValue) bool { return item != nil && item[name] != nil }, nil
+	case "attribute_not_exists":
+		return func(item map[string]*db.AttributeValue) bool { return item == nil || item[name] == nil }, nil
+	}
+	return nil, validationError(fmt.Sprintf("dynamodbtest: unsupported ConditionExpression: %s", *expr))
+}
+
+// compare - Orders two scalar values of the same type, ok is false if they cannot be compared
+func compare(a, b *db.AttributeValue) (int, bool) {
+	switch {
+	case a.S != nil && b.S != nil:
+		return strings.Compare(*a.S, *b.S), true
+	case a.N != nil && b.N != nil:
+		x, okx := new(big.Float).SetString(*a.N)
+		y, oky := new(big.Float).SetString(*b.N)
+		if !okx || !oky {
+			return 0, false
+		}
+		return x.Cmp(y), true
+	case a.B != nil && b.B != nil:
+		return bytes.Compare(a.B, b.B), true
+	}
+	return 0, false
+}
+
+// attributeType - Returns the scalar type of the value, or "" if it is not a scalar key type
+func attributeType(av *db.AttributeValue) string {
+	switch {
+	case av == nil:
+		return ""
+	case av.S != nil:
+		return db.ScalarAttributeTypeS
+	case av.N != nil:
+		return db.ScalarAttributeTypeN
+	case av.B != nil:
+		return db.ScalarAttributeTypeB
+	}
+	return ""
+}
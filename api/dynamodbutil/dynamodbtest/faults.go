@@ -0,0 +1,110 @@
+package dynamodbtest
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Operation names used to inject faults and count calls, the WithContext and Pages variants count as the operation itself
+const (
+	AnyOperation                = "*"
+	OpCreateTable               = "CreateTable"
+	OpDescribeTable             = "DescribeTable"
+	OpDeleteTable               = "DeleteTable"
+	OpGetItem                   = "GetItem"
+	OpPutItem                   = "PutItem"
+	OpDeleteItem                = "DeleteItem"
+	OpQuery                     = "Query"
+	OpScan                      = "Scan"
+	OpBatchWriteItem            = "BatchWriteItem"
+	OpBatchGetItem              = "BatchGetItem"
+	OpUpdateTimeToLive          = "UpdateTimeToLive"
+	OpDescribeTimeToLive        = "DescribeTimeToLive"
+	OpUpdateContinuousBackups   = "UpdateContinuousBackups"
+	OpDescribeContinuousBackups = "DescribeContinuousBackups"
+)
+
+// Fault - Replaces or alters the normal result of an operation
+type Fault struct {
+	// Err - Returned instead of performing the operation
+	Err error
+	// Unprocessed - BatchWriteItem and BatchGetItem leave up to this many requests unprocessed
+	Unprocessed int
+	// Delay - Waits this long, or until the context is cancelled, before performing the operation
+	Delay time.Duration
+	// Times - Number of calls the fault applies to, 0 means every call
+	Times int
+}
+
+// ErrorFault - Fails the operation with err
+func ErrorFault(err error) Fault {
+	return Fault{Err: err}
+}
+
+// ThrottleFault - Fails the operation with a ProvisionedThroughputExceededException
+func ThrottleFault() Fault {
+	return Fault{Err: awserr.New(db.ErrCodeProvisionedThroughputExceededException, "dynamodbtest: throughput exceeded", nil)}
+}
+
+// UnprocessedFault - Leaves up to n requests of a batch operation unprocessed
+func UnprocessedFault(n int) Fault {
+	return Fault{Unprocessed: n}
+}
+
+// SlowFault - Waits for d before performing the operation
+func SlowFault(d time.Duration) Fault {
+	return Fault{Delay: d}
+}
+
+// Inject - Applies the fault to calls of the operation, or to every call if op is AnyOperation
+// Faults for the same operation are applied in the order they were injected
+func (d *DB) Inject(op string, f Fault) {
+	d.faultsMu.Lock()
+	defer d.faultsMu.Unlock()
+	d.faults[op] = append(d.faults[op], &f)
+}
+
+// ClearFaults - Removes every injected fault
+func (d *DB) ClearFaults() {
+	d.faultsMu.Lock()
+	defer d.faultsMu.Unlock()
+	d.faults = map[string][]*Fault{}
+}
+
+// Calls - Returns the number of calls of the operation, or of every operation if op is AnyOperation
+func (d *DB) Calls(op string) int {
+	d.faultsMu.Lock()
+	defer d.faultsMu.Unlock()
+	if op == AnyOperation {
+		total := 0
+		for _, n := range d.calls {
+			total += n
+		}
+		return total
+	}
+	return d.calls[op]
+}
+
+// nextFault - Returns the fault to apply to a call of op, consuming one use of it
+func (d *DB) nextFault(op string) *Fault {
+	d.faultsMu.Lock()
+	defer d.faultsMu.Unlock()
+	d.calls[op]++
+	for _, key := range []string{op, AnyOperation} {
+		faults := d.faults[key]
+		if len(faults) == 0 {
+			continue
+		}
+		f := faults[0]
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				d.faults[key] = faults[1:]
+			}
+		}
+		return f
+	}
+	return nil
+}
@@ -0,0 +1,588 @@
+package dynamodbtest
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+)
+
+const (
+	maxBatchWriteSize = 25
+	maxBatchGetSize   = 100
+)
+
+// copyItem - Deep copies an item so callers cannot modify stored data
+func copyItem(item map[string]*db.AttributeValue) map[string]*db.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	out := make(map[string]*db.AttributeValue, len(item))
+	for k, v := range item {
+		out[k] = copyValue(v)
+	}
+	return out
+}
+
+func copyValue(v *db.AttributeValue) *db.AttributeValue {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	if v.B != nil {
+		c.B = append([]byte{}, v.B...)
+	}
+	if v.BS != nil {
+		c.BS = make([][]byte, len(v.BS))
+		for i, b := range v.BS {
+			c.BS[i] = append([]byte{}, b...)
+		}
+	}
+	if v.SS != nil {
+		c.SS = aws.StringSlice(aws.StringValueSlice(v.SS))
+	}
+	if v.NS != nil {
+		c.NS = aws.StringSlice(aws.StringValueSlice(v.NS))
+	}
+	if v.L != nil {
+		c.L = make([]*db.AttributeValue, len(v.L))
+		for i, e := range v.L {
+			c.L[i] = copyValue(e)
+		}
+	}
+	if v.M != nil {
+		c.M = copyItem(v.M)
+	}
+	return &c
+}
+
+// project - Copies the item keeping only the named attributes, or every attribute if names is nil
+func project(item map[string]*db.AttributeValue, names []string) map[string]*db.AttributeValue {
+	if names == nil {
+		return copyItem(item)
+	}
+	out := map[string]*db.AttributeValue{}
+	for _, name := range names {
+		if v, ok := item[name]; ok {
+			out[name] = copyValue(v)
+		}
+	}
+	return out
+}
+
+func valueString(av *db.AttributeValue) string {
+	switch {
+	case av == nil:
+		return ""
+	case av.S != nil:
+		return "S:" + *av.S
+	case av.N != nil:
+		// DynamoDB compares numbers by value, so 1.50 and 1.5 are the same key
+		return "N:" + dynamodbutil.CanonicalNumber(*av.N)
+	case av.B != nil:
+		return "B:" + base64.StdEncoding.EncodeToString(av.B)
+	}
+	return av.String()
+}
+
+// signature - Identifies an item by the given key attributes
+func signature(ks keySchema, item map[string]*db.AttributeValue) string {
+	parts := []string{}
+	for _, name := range ks.names() {
+		parts = append(parts, name+"="+valueString(item[name]))
+	}
+	return strings.Join(parts, "|")
+}
+
+// validateKey - Checks the key holds exactly the table's key attributes with their defined types
+func (t *table) validateKey(key map[string]*db.AttributeValue) error {
+	if len(key) != len(t.key.names()) {
+		return validationError("The provided key element does not match the schema")
+	}
+	return t.validateKeyAttributes(key)
+}
+
+// validateKeyAttributes - Checks the item holds the table's key attributes with their defined types
+func (t *table) validateKeyAttributes(item map[string]*db.AttributeValue) error {
+	for _, name := range t.key.names() {
+		if attributeType(item[name]) != t.types[name] {
+			return validationError(fmt.Sprintf("One or more parameter values were invalid: Missing the key %s or type mismatch in the item", name))
+		}
+	}
+	return nil
+}
+
+// compareItems - Orders items by the HASH then RANGE key of ks, ties are ordered by the table key
+func (t *table) compareItems(ks keySchema, a map[string]*db.AttributeValue, b map[string]*db.AttributeValue) int {
+	if c, _ := compare(a[ks.hash], b[ks.hash]); c != 0 {
+		return c
+	}
+	if ks.rng != "" {
+		if c, _ := compare(a[ks.rng], b[ks.rng]); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(signature(t.key, a), signature(t.key, b))
+}
+
+// sorted - Returns the items holding every attribute of ks in compareItems order
+func (t *table) sorted(ks keySchema, match func(map[string]*db.AttributeValue) bool) []map[string]*db.AttributeValue {
+	items := []map[string]*db.AttributeValue{}
+	for _, item := range t.items {
+		complete := true
+		for _, name := range ks.names() {
+			if attributeType(item[name]) == "" {
+				complete = false
+			}
+		}
+		if complete && (match == nil || match(item)) {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return t.compareItems(ks, items[i], items[j]) < 0
+	})
+	return items
+}
+
+// page - Applies ExclusiveStartKey and Limit to items sorted forward or in reverse, returning the LastEvaluatedKey if more items remain
+// Like DynamoDB, the page starts after the position of the start key even if its item has since been deleted
+func (t *table) page(items []map[string]*db.AttributeValue, ks keySchema, forward bool, start map[string]*db.AttributeValue, limit *int64) ([]map[string]*db.AttributeValue, map[string]*db.AttributeValue) {
+	if len(start) > 0 {
+		i := sort.Search(len(items), func(i int) bool {
+			c := t.compareItems(ks, items[i], start)
+			if forward {
+				return c > 0
+			}
+			return c < 0
+		})
+		items = items[i:]
+	}
+	if limit == nil || int(*limit) >= len(items) {
+		return items, nil
+	}
+	items = items[:*limit]
+	last := items[len(items)-1]
+	lastKey := map[string]*db.AttributeValue{}
+	for _, name := range append(t.key.names(), ks.names()...) {
+		lastKey[name] = copyValue(last[name])
+	}
+	return items, lastKey
+}
+
+func (d *DB) GetItem(input *db.GetItemInput) (*db.GetItemOutput, error) {
+	return d.GetItemWithContext(context.Background(), input)
+}
+
+func (d *DB) GetItemWithContext(ctx aws.Context, input *db.GetItemInput, _ ...request.Option) (*db.GetItemOutput, error) {
+	if _, err := d.begin(ctx, OpGetItem); err != nil {
+		return nil, err
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, err := d.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.validateKey(input.Key); err != nil {
+		return nil, err
+	}
+	names, err := parseProjection(input.ProjectionExpression, expressionContext{names: input.ExpressionAttributeNames})
+	if err != nil {
+		return nil, err
+	}
+	item, ok := t.items[signature(t.key, input.Key)]
+	if !ok {
+		return &db.GetItemOutput{}, nil
+	}
+	return &db.GetItemOutput{Item: project(item, names)}, nil
+}
+
+func (d *DB) PutItem(input *db.PutItemInput) (*db.PutItemOutput, error) {
+	return d.PutItemWithContext(context.Background(), input)
+}
+
+// PutItemWithContext - Supports attribute_exists/attribute_not_exists conditions and ReturnValues ALL_OLD
+func (d *DB) PutItemWithContext(ctx aws.Context, input *db.PutItemInput, _ ...request.Option) (*db.PutItemOutput, error) {
+	if _, err := d.begin(ctx, OpPutItem); err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, err := d.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.validateKeyAttributes(input.Item); err != nil {
+		return nil, err
+	}
+	condition, err := parseCondition(input.ConditionExpression, expressionContext{names: input.ExpressionAttributeNames, values: input.ExpressionAttributeValues})
+	if err != nil {
+		return nil, err
+	}
+	sig := signature(t.key, input.Item)
+	old := t.items[sig]
+	if condition != nil && !condition(old) {
+		return nil, conditionalCheckFailed()
+	}
+	t.items[sig] = copyItem(input.Item)
+	out := &db.PutItemOutput{}
+	if aws.StringValue(input.ReturnValues) == db.ReturnValueAllOld {
+		out.Attributes = copyItem(old)
+	}
+	return out, nil
+}
+
+func (d *DB) DeleteItem(input *db.DeleteItemInput) (*db.DeleteItemOutput, error) {
+	return d.DeleteItemWithContext(context.Background(), input)
+}
+
+// DeleteItemWithContext - Supports attribute_exists/attribute_not_exists conditions and ReturnValues ALL_OLD
+func (d *DB) DeleteItemWithContext(ctx aws.Context, input *db.DeleteItemInput, _ ...request.Option) (*db.DeleteItemOutput, error) {
+	if _, err := d.begin(ctx, OpDeleteItem); err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, err := d.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.validateKey(input.Key); err != nil {
+		return nil, err
+	}
+	condition, err := parseCondition(input.ConditionExpression, expressionContext{names: input.ExpressionAttributeNames, values: input.ExpressionAttributeValues})
+	if err != nil {
+		return nil, err
+	}
+	sig := signature(t.key, input.Key)
+	old := t.items[sig]
+	if condition != nil && !condition(old) {
+		return nil, conditionalCheckFailed()
+	}
+	delete(t.items, sig)
+	out := &db.DeleteItemOutput{}
+	if aws.StringValue(input.ReturnValues) == db.ReturnValueAllOld {
+		out.Attributes = copyItem(old)
+	}
+	return out, nil
+}
+
+func (d *DB) Query(input *db.QueryInput) (*db.QueryOutput, error) {
+	return d.QueryWithContext(context.Background(), input)
+}
+
+// QueryWithContext - Supports key condition expressions on tables and indexes, ScanIndexForward, Limit, ExclusiveStartKey and ProjectionExpression
+// FilterExpression is not supported and returns a ValidationException
+func (d *DB) QueryWithContext(ctx aws.Context, input *db.QueryInput, _ ...request.Option) (*db.QueryOutput, error) {
+	if _, err := d.begin(ctx, OpQuery); err != nil {
+		return nil, err
+	}
+	if input.FilterExpression != nil || input.KeyConditions != nil || input.QueryFilter != nil {
+		return nil, validationError("dynamodbtest: only KeyConditionExpression is supported by Query")
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, err := d.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	ks := t.key
+	if input.IndexName != nil {
+		var ok bool
+		if ks, ok = t.indexes[aws.StringValue(input.IndexName)]; !ok {
+			return nil, validationError(fmt.Sprintf("The table does not have the specified index: %s", aws.StringValue(input.IndexName)))
+		}
+	}
+	e := expressionContext{names: input.ExpressionAttributeNames, values: input.ExpressionAttributeValues}
+	conditions, err := parseKeyCondition(aws.StringValue(input.KeyConditionExpression), e)
+	if err != nil {
+		return nil, err
+	}
+	hashFound := false
+	for _, c := range conditions {
+		switch {
+		case c.name == ks.hash && c.op == "=":
+			hashFound = true
+		case c.name == ks.rng && ks.rng != "":
+		default:
+			return nil, validationError(fmt.Sprintf("Query key condition not supported: %s %s", c.name, c.op))
+		}
+	}
+	if !hashFound {
+		return nil, validationError("Query condition missed key schema element: " + ks.hash)
+	}
+	names, err := parseProjection(input.ProjectionExpression, e)
+	if err != nil {
+		return nil, err
+	}
+	items := t.sorted(ks, func(item map[string]*db.AttributeValue) bool {
+		for _, c := range conditions {
+			if !c.matches(item[c.name]) {
+				return false
+			}
+		}
+		return true
+	})
+	forward := input.ScanIndexForward == nil || *input.ScanIndexForward
+	if !forward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	items, lastKey := t.page(items, ks, forward, input.ExclusiveStartKey, input.Limit)
+	out := &db.QueryOutput{
+		Count:            aws.Int64(int64(len(items))),
+		ScannedCount:     aws.Int64(int64(len(items))),
+		LastEvaluatedKey: lastKey,
+	}
+	if aws.StringValue(input.Select) != db.SelectCount {
+		out.Items = make([]map[string]*db.AttributeValue, len(items))
+		for i, item := range items {
+			out.Items[i] = project(item, names)
+		}
+	}
+	return out, nil
+}
+
+func (d *DB) QueryPages(input *db.QueryInput, fn func(*db.QueryOutput, bool) bool) error {
+	return d.QueryPagesWithContext(context.Background(), input, fn)
+}
+
+func (d *DB) QueryPagesWithContext(ctx aws.Context, input *db.QueryInput, fn func(*db.QueryOutput, bool) bool, _ ...request.Option) error {
+	in := *input
+	for {
+		out, err := d.QueryWithContext(ctx, &in)
+		if err != nil {
+			return err
+		}
+		last := len(out.LastEvaluatedKey) == 0
+		if !fn(out, last) || last {
+			return nil
+		}
+		in.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+func (d *DB) Scan(input *db.ScanInput) (*db.ScanOutput, error) {
+	return d.ScanWithContext(context.Background(), input)
+}
+
+// ScanWithContext - Items are returned ordered by key, supports IndexName, Limit, ExclusiveStartKey and ProjectionExpression
+// FilterExpression and parallel scans are not supported and return a ValidationException
+func (d *DB) ScanWithContext(ctx aws.Context, input *db.ScanInput, _ ...request.Option) (*db.ScanOutput, error) {
+	if _, err := d.begin(ctx, OpScan); err != nil {
+		return nil, err
+	}
+	if input.FilterExpression != nil || input.ScanFilter != nil || input.Segment != nil {
+		return nil, validationError("dynamodbtest: FilterExpression and parallel scans are not supported by Scan")
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, err := d.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	ks := t.key
+	if input.IndexName != nil {
+		var ok bool
+		if ks, ok = t.indexes[aws.StringValue(input.IndexName)]; !ok {
+			return nil, validationError(fmt.Sprintf("The table does not have the specified index: %s", aws.StringValue(input.IndexName)))
+		}
+	}
+	names, err := parseProjection(input.ProjectionExpression, expressionContext{names: input.ExpressionAttributeNames})
+	if err != nil {
+		return nil, err
+	}
+	items, lastKey := t.page(t.sorted(ks, nil), ks, true, input.ExclusiveStartKey, input.Limit)
+	out := &db.ScanOutput{
+		Count:            aws.Int64(int64(len(items))),
+		ScannedCount:     aws.Int64(int64(len(items))),
+		LastEvaluatedKey: lastKey,
+	}
+	if aws.StringValue(input.Select) != db.SelectCount {
+		out.Items = make([]map[string]*db.AttributeValue, len(items))
+		for i, item := range items {
+			out.Items[i] = project(item, names)
+		}
+	}
+	return out, nil
+}
+
+func (d *DB) ScanPages(input *db.ScanInput, fn func(*db.ScanOutput, bool) bool) error {
+	return d.ScanPagesWithContext(context.Background(), input, fn)
+}
+
+func (d *DB) ScanPagesWithContext(ctx aws.Context, input *db.ScanInput, fn func(*db.ScanOutput, bool) bool, _ ...request.Option) error {
+	in := *input
+	for {
+		out, err := d.ScanWithContext(ctx, &in)
+		if err != nil {
+			return err
+		}
+		last := len(out.LastEvaluatedKey) == 0
+		if !fn(out, last) || last {
+			return nil
+		}
+		in.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+func (d *DB) BatchWriteItem(input *db.BatchWriteItemInput) (*db.BatchWriteItemOutput, error) {
+	return d.BatchWriteItemWithContext(context.Background(), input)
+}
+
+// BatchWriteItemWithContext - Validates the batch like DynamoDB does, UnprocessedFault leaves requests unprocessed
+func (d *DB) BatchWriteItemWithContext(ctx aws.Context, input *db.BatchWriteItemInput, _ ...request.Option) (*db.BatchWriteItemOutput, error) {
+	f, err := d.begin(ctx, OpBatchWriteItem)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	total := 0
+	seen := map[string]bool{}
+	for _, tableName := range sortedKeys(input.RequestItems) {
+		t, err := d.table(aws.String(tableName))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range input.RequestItems[tableName] {
+			total++
+			var key map[string]*db.AttributeValue
+			switch {
+			case r.PutRequest != nil && r.DeleteRequest == nil:
+				key = r.PutRequest.Item
+				err = t.validateKeyAttributes(key)
+			case r.DeleteRequest != nil && r.PutRequest == nil:
+				key = r.DeleteRequest.Key
+				err = t.validateKey(key)
+			default:
+				err = validationError("Supplied WriteRequest must have exactly one of PutRequest or DeleteRequest")
+			}
+			if err != nil {
+				return nil, err
+			}
+			sig := tableName + "|" + signature(t.key, key)
+			if seen[sig] {
+				return nil, validationError("Provided list of item keys contains duplicates")
+			}
+			seen[sig] = true
+		}
+	}
+	if total == 0 || total > maxBatchWriteSize {
+		return nil, validationError(fmt.Sprintf("Member must have length less than or equal to %d and at least 1, got %d", maxBatchWriteSize, total))
+	}
+	unprocessed := 0
+	if f != nil {
+		unprocessed = f.Unprocessed
+	}
+	out := &db.BatchWriteItemOutput{UnprocessedItems: map[string][]*db.WriteRequest{}}
+	for _, tableName := range sortedKeys(input.RequestItems) {
+		t := d.tables[tableName]
+		for _, r := range input.RequestItems[tableName] {
+			if unprocessed > 0 {
+				unprocessed--
+				out.UnprocessedItems[tableName] = append(out.UnprocessedItems[tableName], r)
+				continue
+			}
+			if r.PutRequest != nil {
+				t.items[signature(t.key, r.PutRequest.Item)] = copyItem(r.PutRequest.Item)
+			} else {
+				delete(t.items, signature(t.key, r.DeleteRequest.Key))
+			}
+		}
+	}
+	return out, nil
+}
+
+func (d *DB) BatchGetItem(input *db.BatchGetItemInput) (*db.BatchGetItemOutput, error) {
+	return d.BatchGetItemWithContext(context.Background(), input)
+}
+
+// BatchGetItemWithContext - Validates the batch like DynamoDB does, UnprocessedFault leaves keys unprocessed
+func (d *DB) BatchGetItemWithContext(ctx aws.Context, input *db.BatchGetItemInput, _ ...request.Option) (*db.BatchGetItemOutput, error) {
+	f, err := d.begin(ctx, OpBatchGetItem)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	total := 0
+	for _, tableName := range sortedKeys(input.RequestItems) {
+		t, err := d.table(aws.String(tableName))
+		if err != nil {
+			return nil, err
+		}
+		seen := map[string]bool{}
+		for _, key := range input.RequestItems[tableName].Keys {
+			total++
+			if err := t.validateKey(key); err != nil {
+				return nil, err
+			}
+			sig := signature(t.key, key)
+			if seen[sig] {
+				return nil, validationError("Provided list of item keys contains duplicates")
+			}
+			seen[sig] = true
+		}
+	}
+	if total == 0 || total > maxBatchGetSize {
+		return nil, validationError(fmt.Sprintf("Too many items requested for the BatchGetItem call, got %d", total))
+	}
+	unprocessed := 0
+	if f != nil {
+		unprocessed = f.Unprocessed
+	}
+	out := &db.BatchGetItemOutput{
+		Responses:       map[string][]map[string]*db.AttributeValue{},
+		UnprocessedKeys: map[string]*db.KeysAndAttributes{},
+	}
+	for _, tableName := range sortedKeys(input.RequestItems) {
+		t := d.tables[tableName]
+		ka := input.RequestItems[tableName]
+		names, err := parseProjection(ka.ProjectionExpression, expressionContext{names: ka.ExpressionAttributeNames})
+		if err != nil {
+			return nil, err
+		}
+		out.Responses[tableName] = []map[string]*db.AttributeValue{}
+		for _, key := range ka.Keys {
+			if unprocessed > 0 {
+				unprocessed--
+				if out.UnprocessedKeys[tableName] == nil {
+					rest := *ka
+					rest.Keys = nil
+					out.UnprocessedKeys[tableName] = &rest
+				}
+				out.UnprocessedKeys[tableName].Keys = append(out.UnprocessedKeys[tableName].Keys, key)
+				continue
+			}
+			if item, ok := t.items[signature(t.key, key)]; ok {
+				out.Responses[tableName] = append(out.Responses[tableName], project(item, names))
+			}
+		}
+	}
+	return out, nil
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	switch v := m.(type) {
+	case map[string][]*db.WriteRequest:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]*db.KeysAndAttributes:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -32,58 +33,40 @@ var AttributeTypeTags = []string{"attributetype", "at"}
 
 var KeyTypeTags = []string{"keytype", "kt"}
 
+var GlobalIndexTags = []string{"globalindex", "gsi"}
+
+var LocalIndexTags = []string{"localindex", "lsi"}
+
+// CreateHistoricalTable - Creates the Historical table as declared in serverless/services/dynamodb/serverless.yml
+// Deprecated: Use CreateTableFromStruct with models.Historical, or cmd/tablegen
 func CreateHistoricalTable(ddbClient dynamodbiface.DynamoDBAPI) (*db.CreateTableOutput, error) {
 	input := &dynamodb.CreateTableInput{
 		TableName:   aws.String("Historical"),
-		BillingMode: aws.String(DefaultBillingMode),
+		BillingMode: aws.String(db.BillingModeProvisioned),
 		AttributeDefinitions: []*dynamodb.AttributeDefinition{
-			{
-				AttributeName: aws.String("Date"),
-				AttributeType: aws.String("S"),
-			},
 			{
 				AttributeName: aws.String("Symbol"),
 				AttributeType: aws.String("S"),
 			},
 			{
-				AttributeName: aws.String("Open"),
-				AttributeType: aws.String("N"),
-			},
-			{
-				AttributeName: aws.String("High"),
-				AttributeType: aws.String("N"),
-			},
-			{
-				AttributeName: aws.String("Low"),
-				AttributeType: aws.String("N"),
-			},
-			{
-				AttributeName: aws.String("Close"),
-				AttributeType: aws.String("N"),
-			},
-			{
-				AttributeName: aws.String("Volume"),
-				AttributeType: aws.String("N"),
-			},
-			{
-				AttributeName: aws.String("Change"),
-				AttributeType: aws.String("N"),
-			},
-			{
-				AttributeName: aws.String("ChangePercent"),
-				AttributeType: aws.String("N"),
+				AttributeName: aws.String("Date"),
+				AttributeType: aws.String("S"),
 			},
 		},
 		KeySchema: []*dynamodb.KeySchemaElement{
 			{
-				AttributeName: aws.String("Date"),
+				AttributeName: aws.String("Symbol"),
 				KeyType:       aws.String("HASH"),
 			},
 			{
-				AttributeName: aws.String("Symbol"),
+				AttributeName: aws.String("Date"),
 				KeyType:       aws.String("RANGE"),
 			},
 		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(1),
+		},
 	}
 	return ddbClient.CreateTable(input)
 }
@@ -92,6 +75,12 @@ func CreateHistoricalTable(ddbClient dynamodbiface.DynamoDBAPI) (*db.CreateTable
 // AttributeName: Uses the field's name
 // AttributeType: Accepts both `at` and `attributetype` struct tags.
 // KeyType: Accepts both `kt` and `keytype` struct tags
+// Global Secondary Indexes: Accepts both `gsi` and `globalindex` struct tags
+// Local Secondary Indexes: Accepts both `lsi` and `localindex` struct tags, the table's HASH key is added to their key schema
+// Table Options: Table name, billing, streams, TTL and point in time recovery are taken from TableOptions if s implements Tabler
+// Index tags hold one or more `IndexName,Role[,Projection]` specs separated by `;`, e.g. `at:"S" gsi:"SectorIndex,HASH,KEYS_ONLY"`
+// Index Role: HASH or RANGE for key attributes, or INCLUDE to project a non-key attribute into the index
+// Index Projection: ALL (default), KEYS_ONLY or INCLUDE and only needs to be set on one field of the index
 func CreateTableInputFromStruct(s interface{}) (*dynamodb.CreateTableInput, error) {
 	v := reflect.ValueOf(s)
 	if v.Kind() == reflect.Ptr {
@@ -101,78 +90,107 @@ func CreateTableInputFromStruct(s interface{}) (*dynamodb.CreateTableInput, erro
 	if t.Kind() != reflect.Struct {
 		return nil, errors.New("Error: Input must be a struct or pointer to a struct")
 	}
-	tableName := t.Name()
 	input := &dynamodb.CreateTableInput{
 		AttributeDefinitions: []*dynamodb.AttributeDefinition{},
 		KeySchema:            []*dynamodb.KeySchemaElement{},
-		BillingMode:          aws.String(DefaultBillingMode),
-		TableName:            aws.String(tableName),
 	}
+	globalIndexes := &indexSet{}
+	localIndexes := &indexSet{}
 	for i := 0; i < v.NumField(); i++ {
 		f := t.Field(i)
 		// Attribute Name
 		attributeName := f.Name
 		// Attribute Type
-		tagFound := false
-		attributeType := ""
-		for _, tag := range AttributeTypeTags {
-			val, ok := f.Tag.Lookup(tag)
-			if ok {
-				attributeType = val
-				tagFound = true
+		attributeType, hasAttributeType := lookupTag(f, AttributeTypeTags)
+		// Secondary Indexes
+		isIndexKey := false
+		if spec, ok := lookupTag(f, GlobalIndexTags); ok {
+			isKey, err := globalIndexes.add(attributeName, spec)
+			if err != nil {
+				return nil, err
 			}
+			isIndexKey = isIndexKey || isKey
 		}
-		if !tagFound {
+		if spec, ok := lookupTag(f, LocalIndexTags); ok {
+			isKey, err := localIndexes.add(attributeName, spec)
+			if err != nil {
+				return nil, err
+			}
+			isIndexKey = isIndexKey || isKey
+		}
+		if !hasAttributeType {
+			if isIndexKey {
+				return nil, fmt.Errorf("Error: index key %s is missing an Attribute Type", attributeName)
+			}
 			continue
 		}
 		if _, ok := ValidAttributeTypeMap[attributeType]; !ok {
 			return nil, fmt.Errorf("Error: %s is not a valid Attribute Type", attributeType)
 		}
 		// Key Type
-		tagFound = false
-		keyType := ""
-		for _, tag := range KeyTypeTags {
-			val, ok := f.Tag.Lookup(tag)
-			if ok {
-				keyType = val
-				tagFound = true
+		keyType, tagFound := lookupTag(f, KeyTypeTags)
+		if tagFound {
+			if _, ok := ValidKeyTypeMap[keyType]; !ok {
+				return nil, fmt.Errorf("Error: %s is not a valid Key Type", keyType)
 			}
+			input.KeySchema = append(input.KeySchema, &dynamodb.KeySchemaElement{
+				AttributeName: aws.String(attributeName),
+				KeyType:       aws.String(keyType),
+			})
 		}
-		if !tagFound {
+		if !tagFound && !isIndexKey {
 			continue
 		}
-		if _, ok := ValidKeyTypeMap[keyType]; !ok {
-			return nil, fmt.Errorf("Error: %s is not a valid Key Type", keyType)
-		}
-		// Add items to CreateTableInput
+		// Add items to CreateTableInput, only key attributes of the table or an index may be defined
 		input.AttributeDefinitions = append(input.AttributeDefinitions, &dynamodb.AttributeDefinition{
 			AttributeName: aws.String(attributeName),
 			AttributeType: aws.String(attributeType),
 		})
-		input.KeySchema = append(input.KeySchema, &dynamodb.KeySchemaElement{
-			AttributeName: aws.String(attributeName),
-			KeyType:       aws.String(keyType),
-		})
+	}
+	sortKeySchema(input.KeySchema)
+	gsis, err := globalIndexes.globalSecondaryIndexes()
+	if err != nil {
+		return nil, err
+	}
+	input.GlobalSecondaryIndexes = gsis
+	lsis, err := localIndexes.localSecondaryIndexes(input.KeySchema)
+	if err != nil {
+		return nil, err
+	}
+	input.LocalSecondaryIndexes = lsis
+	if err := applyTableOptions(input, TableOptionsFromStruct(s)); err != nil {
+		return nil, err
 	}
 	return input, nil
 }
 
-// PutItemInputFromStruct - Generates PutItemInput from the given struct
+// lookupTag - Returns the value of the first of the given tags set on the field
+func lookupTag(f reflect.StructField, tags []string) (string, bool) {
+	for _, tag := range tags {
+		if val, ok := f.Tag.Lookup(tag); ok {
+			return val, true
+		}
+	}
+	return "", false
+}
+
+// sortKeySchema - Places the HASH key before the RANGE key as required by DynamoDB
+func sortKeySchema(schema []*dynamodb.KeySchemaElement) {
+	sort.SliceStable(schema, func(i, j int) bool {
+		return aws.StringValue(schema[i].KeyType) == dynamodb.KeyTypeHash && aws.StringValue(schema[j].KeyType) != dynamodb.KeyTypeHash
+	})
+}
+
+// PutItemInputFromStruct - Generates PutItemInput from the given struct, the table name is taken from its TableOptions
 func PutItemInputFromStruct(item interface{}) (*dynamodb.PutItemInput, error) {
 	// create the attributevalue
 	av, err := dynamodbattribute.MarshalMap(item)
 	if err != nil {
 		return nil, err
 	}
-	// check if it contains any inferred attribute type tags. If so, modify that field accordingly
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	t := v.Type()
 	input := &dynamodb.PutItemInput{
 		Item:      av,
-		TableName: aws.String(t.Name()),
+		TableName: aws.String(TableNameFromStruct(item)),
 	}
 	return input, nil
 }
@@ -196,6 +214,7 @@ func PutRequestsFromSlice(arr interface{}) ([]*db.PutRequest, error) {
 }
 
 // ConvertToBatchPutRequest - Consolidates the input slice of PutRequests to a BatchWriteRequest
+// The caller is responsible for UnprocessedItems, BatchWriter handles them along with chunking and concurrency
 func ConvertToBatchPutRequest(requests []*db.PutRequest, tableName string) []*db.BatchWriteItemInput {
 	inputs := []*db.BatchWriteItemInput{}
 	l := len(requests)
@@ -0,0 +1,179 @@
+package dynamodbutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// IndexRoleInclude - Index role projecting a non-key attribute into the index
+const IndexRoleInclude = "INCLUDE"
+
+var ValidProjectionTypeMap = map[string]bool{
+	dynamodb.ProjectionTypeAll:      true,
+	dynamodb.ProjectionTypeKeysOnly: true,
+	dynamodb.ProjectionTypeInclude:  true,
+}
+
+type indexDefinition struct {
+	name       string
+	keySchema  []*dynamodb.KeySchemaElement
+	projection string
+	nonKey     []*string
+}
+
+// indexSet - Secondary indexes of one kind, kept in the order they are first seen
+type indexSet struct {
+	indexes []*indexDefinition
+}
+
+func (s *indexSet) get(name string) *indexDefinition {
+	for _, idx := range s.indexes {
+		if idx.name == name {
+			return idx
+		}
+	}
+	idx := &indexDefinition{name: name}
+	s.indexes = append(s.indexes, idx)
+	return idx
+}
+
+// add - Parses the tag of the given attribute, isKey is true if the attribute is a key of any of the indexes
+func (s *indexSet) add(attributeName string, tag string) (isKey bool, err error) {
+	for _, spec := range strings.Split(tag, ";") {
+		parts := strings.Split(spec, ",")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return false, fmt.Errorf("Error: %s is not a valid index spec, expected IndexName,Role[,Projection]", spec)
+		}
+		idx := s.get(parts[0])
+		role := parts[1]
+		switch {
+		case role == IndexRoleInclude:
+			idx.nonKey = append(idx.nonKey, aws.String(attributeName))
+		case ValidKeyTypeMap[role]:
+			idx.keySchema = append(idx.keySchema, &dynamodb.KeySchemaElement{
+				AttributeName: aws.String(attributeName),
+				KeyType:       aws.String(role),
+			})
+			isKey = true
+		default:
+			return false, fmt.Errorf("Error: %s is not a valid Index Role", role)
+		}
+		if len(parts) == 3 {
+			projection := parts[2]
+			if _, ok := ValidProjectionTypeMap[projection]; !ok {
+				return false, fmt.Errorf("Error: %s is not a valid Projection Type", projection)
+			}
+			if idx.projection != "" && idx.projection != projection {
+				return false, fmt.Errorf("Error: index %s has conflicting Projection Types %s and %s", idx.name, idx.projection, projection)
+			}
+			idx.projection = projection
+		}
+	}
+	return isKey, nil
+}
+
+func (idx *indexDefinition) buildProjection() (*dynamodb.Projection, error) {
+	projection := idx.projection
+	if projection == "" {
+		projection = dynamodb.ProjectionTypeAll
+		if len(idx.nonKey) > 0 {
+			projection = dynamodb.ProjectionTypeInclude
+		}
+	}
+	if projection == dynamodb.ProjectionTypeInclude && len(idx.nonKey) == 0 {
+		return nil, fmt.Errorf("Error: index %s uses the INCLUDE Projection Type without any INCLUDE attributes", idx.name)
+	}
+	if projection != dynamodb.ProjectionTypeInclude && len(idx.nonKey) > 0 {
+		return nil, fmt.Errorf("Error: index %s has INCLUDE attributes but uses the %s Projection Type", idx.name, projection)
+	}
+	p := &dynamodb.Projection{ProjectionType: aws.String(projection)}
+	if len(idx.nonKey) > 0 {
+		p.NonKeyAttributes = idx.nonKey
+	}
+	return p, nil
+}
+
+// validateKeySchema - An index needs exactly one HASH key and at most one RANGE key
+func (idx *indexDefinition) validateKeySchema() error {
+	hash, rng := 0, 0
+	for _, k := range idx.keySchema {
+		if aws.StringValue(k.KeyType) == dynamodb.KeyTypeHash {
+			hash++
+		} else {
+			rng++
+		}
+	}
+	if hash != 1 || rng > 1 {
+		return fmt.Errorf("Error: index %s must have exactly one HASH key and at most one RANGE key", idx.name)
+	}
+	return nil
+}
+
+func (s *indexSet) globalSecondaryIndexes() ([]*dynamodb.GlobalSecondaryIndex, error) {
+	if len(s.indexes) == 0 {
+		return nil, nil
+	}
+	gsis := []*dynamodb.GlobalSecondaryIndex{}
+	for _, idx := range s.indexes {
+		sortKeySchema(idx.keySchema)
+		if err := idx.validateKeySchema(); err != nil {
+			return nil, err
+		}
+		projection, err := idx.buildProjection()
+		if err != nil {
+			return nil, err
+		}
+		gsis = append(gsis, &dynamodb.GlobalSecondaryIndex{
+			IndexName:  aws.String(idx.name),
+			KeySchema:  idx.keySchema,
+			Projection: projection,
+		})
+	}
+	return gsis, nil
+}
+
+// localSecondaryIndexes - Local indexes share the HASH key of the table, so only their RANGE key is tagged
+func (s *indexSet) localSecondaryIndexes(tableKeySchema []*dynamodb.KeySchemaElement) ([]*dynamodb.LocalSecondaryIndex, error) {
+	if len(s.indexes) == 0 {
+		return nil, nil
+	}
+	var tableHash, tableRange *dynamodb.KeySchemaElement
+	for _, k := range tableKeySchema {
+		if aws.StringValue(k.KeyType) == dynamodb.KeyTypeHash {
+			tableHash = k
+		} else {
+			tableRange = k
+		}
+	}
+	if tableHash == nil || tableRange == nil {
+		return nil, fmt.Errorf("Error: local secondary indexes require the table to have both a HASH and a RANGE key")
+	}
+	lsis := []*dynamodb.LocalSecondaryIndex{}
+	for _, idx := range s.indexes {
+		for _, k := range idx.keySchema {
+			if aws.StringValue(k.KeyType) != dynamodb.KeyTypeRange {
+				return nil, fmt.Errorf("Error: local index %s may only tag its RANGE key", idx.name)
+			}
+		}
+		if len(idx.keySchema) != 1 {
+			return nil, fmt.Errorf("Error: local index %s must have exactly one RANGE key", idx.name)
+		}
+		idx.keySchema = append([]*dynamodb.KeySchemaElement{{
+			AttributeName: tableHash.AttributeName,
+			KeyType:       aws.String(dynamodb.KeyTypeHash),
+		}}, idx.keySchema...)
+		projection, err := idx.buildProjection()
+		if err != nil {
+			return nil, err
+		}
+		lsis = append(lsis, &dynamodb.LocalSecondaryIndex{
+			IndexName:  aws.String(idx.name),
+			KeySchema:  idx.keySchema,
+			Projection: projection,
+		})
+	}
+	return lsis, nil
+}
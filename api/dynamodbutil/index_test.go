@@ -0,0 +1,196 @@
+package dynamodbutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type gsiDefault struct {
+	Symbol string `at:"S" kt:"HASH" gsi:"SectorIndex,RANGE"`
+	Sector string `at:"S" gsi:"SectorIndex,HASH"`
+	Name   string
+}
+
+type gsiKeysOnly struct {
+	Symbol string `at:"S" kt:"HASH"`
+	Sector string `at:"S" globalindex:"SectorIndex,HASH,KEYS_ONLY"`
+}
+
+type gsiInclude struct {
+	Symbol    string  `at:"S" kt:"HASH"`
+	Sector    string  `at:"S" gsi:"SectorIndex,HASH"`
+	MarketCap float64 `at:"N" gsi:"SectorIndex,RANGE;CapIndex,HASH,KEYS_ONLY"`
+	Name      string  `gsi:"SectorIndex,INCLUDE"`
+	CEO       string  `gsi:"SectorIndex,INCLUDE"`
+}
+
+type lsiRange struct {
+	Symbol string  `at:"S" kt:"HASH"`
+	Date   string  `at:"S" kt:"RANGE"`
+	Close  float64 `at:"N" lsi:"CloseIndex,RANGE,KEYS_ONLY"`
+	Volume int     `at:"N" localindex:"VolumeIndex,RANGE"`
+}
+
+type lsiHashOnlyTable struct {
+	Symbol string  `at:"S" kt:"HASH"`
+	Close  float64 `at:"N" lsi:"CloseIndex,RANGE"`
+}
+
+type lsiTaggedHash struct {
+	Symbol string  `at:"S" kt:"HASH"`
+	Date   string  `at:"S" kt:"RANGE"`
+	Close  float64 `at:"N" lsi:"CloseIndex,HASH"`
+}
+
+type gsiTwoHashes struct {
+	Symbol string `at:"S" kt:"HASH" gsi:"SectorIndex,HASH"`
+	Sector string `at:"S" gsi:"SectorIndex,HASH"`
+}
+
+type gsiNoHash struct {
+	Symbol string `at:"S" kt:"HASH" gsi:"SectorIndex,RANGE"`
+}
+
+type gsiInvalidRole struct {
+	Symbol string `at:"S" kt:"HASH"`
+	Sector string `at:"S" gsi:"SectorIndex,SORT"`
+}
+
+type gsiInvalidSpec struct {
+	Symbol string `at:"S" kt:"HASH"`
+	Sector string `at:"S" gsi:"SectorIndex"`
+}
+
+type gsiInvalidProjection struct {
+	Symbol string `at:"S" kt:"HASH"`
+	Sector string `at:"S" gsi:"SectorIndex,HASH,SOME"`
+}
+
+type gsiConflictingProjection struct {
+	Symbol string `at:"S" kt:"HASH" gsi:"SectorIndex,RANGE,ALL"`
+	Sector string `at:"S" gsi:"SectorIndex,HASH,KEYS_ONLY"`
+}
+
+type gsiIncludeWithoutAttributes struct {
+	Symbol string `at:"S" kt:"HASH"`
+	Sector string `at:"S" gsi:"SectorIndex,HASH,INCLUDE"`
+}
+
+type gsiIncludeWithKeysOnly struct {
+	Symbol string `at:"S" kt:"HASH"`
+	Sector string `at:"S" gsi:"SectorIndex,HASH,KEYS_ONLY"`
+	Name   string `gsi:"SectorIndex,INCLUDE"`
+}
+
+type gsiKeyWithoutType struct {
+	Symbol string `at:"S" kt:"HASH"`
+	Sector string `gsi:"SectorIndex,HASH"`
+}
+
+func describeKeySchema(ks []*dynamodb.KeySchemaElement) string {
+	parts := []string{}
+	for _, k := range ks {
+		parts = append(parts, aws.StringValue(k.AttributeName)+" "+aws.StringValue(k.KeyType))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func describeIndex(kind string, name *string, ks []*dynamodb.KeySchemaElement, p *dynamodb.Projection) string {
+	return fmt.Sprintf("%s %s [%s] %s%v", kind, aws.StringValue(name), describeKeySchema(ks), aws.StringValue(p.ProjectionType), aws.StringValueSlice(p.NonKeyAttributes))
+}
+
+// describeInput - Summarises the attributes, key schema and indexes of the input on one line each
+func describeInput(input *dynamodb.CreateTableInput) []string {
+	attributes := []string{}
+	for _, a := range input.AttributeDefinitions {
+		attributes = append(attributes, aws.StringValue(a.AttributeName)+":"+aws.StringValue(a.AttributeType))
+	}
+	lines := []string{strings.Join(attributes, " "), describeKeySchema(input.KeySchema)}
+	for _, g := range input.GlobalSecondaryIndexes {
+		lines = append(lines, describeIndex("GSI", g.IndexName, g.KeySchema, g.Projection))
+	}
+	for _, l := range input.LocalSecondaryIndexes {
+		lines = append(lines, describeIndex("LSI", l.IndexName, l.KeySchema, l.Projection))
+	}
+	return lines
+}
+
+func TestCreateTableInputFromStructIndexes(t *testing.T) {
+	tests := []struct {
+		name  string
+		model interface{}
+		want  []string
+		err   string
+	}{
+		{
+			name:  "GSI defaults to ALL and only defines key attributes",
+			model: gsiDefault{},
+			want: []string{
+				"Symbol:S Sector:S",
+				"Symbol HASH",
+				"GSI SectorIndex [Sector HASH, Symbol RANGE] ALL[]",
+			},
+		},
+		{
+			name:  "GSI KEYS_ONLY",
+			model: gsiKeysOnly{},
+			want: []string{
+				"Symbol:S Sector:S",
+				"Symbol HASH",
+				"GSI SectorIndex [Sector HASH] KEYS_ONLY[]",
+			},
+		},
+		{
+			name:  "INCLUDE attributes infer the projection and a field can key several indexes",
+			model: gsiInclude{},
+			want: []string{
+				"Symbol:S Sector:S MarketCap:N",
+				"Symbol HASH",
+				"GSI SectorIndex [Sector HASH, MarketCap RANGE] INCLUDE[Name CEO]",
+				"GSI CapIndex [MarketCap HASH] KEYS_ONLY[]",
+			},
+		},
+		{
+			name:  "LSI gets the table HASH key",
+			model: lsiRange{},
+			want: []string{
+				"Symbol:S Date:S Close:N Volume:N",
+				"Symbol HASH, Date RANGE",
+				"LSI CloseIndex [Symbol HASH, Close RANGE] KEYS_ONLY[]",
+				"LSI VolumeIndex [Symbol HASH, Volume RANGE] ALL[]",
+			},
+		},
+		{name: "LSI on a table without a RANGE key", model: lsiHashOnlyTable{}, err: "both a HASH and a RANGE key"},
+		{name: "LSI tagging a HASH key", model: lsiTaggedHash{}, err: "may only tag its RANGE key"},
+		{name: "GSI with two HASH keys", model: gsiTwoHashes{}, err: "exactly one HASH key"},
+		{name: "GSI without a HASH key", model: gsiNoHash{}, err: "exactly one HASH key"},
+		{name: "invalid role", model: gsiInvalidRole{}, err: "SORT is not a valid Index Role"},
+		{name: "invalid spec", model: gsiInvalidSpec{}, err: "not a valid index spec"},
+		{name: "invalid projection", model: gsiInvalidProjection{}, err: "SOME is not a valid Projection Type"},
+		{name: "conflicting projections", model: gsiConflictingProjection{}, err: "conflicting Projection Types"},
+		{name: "INCLUDE projection without attributes", model: gsiIncludeWithoutAttributes{}, err: "without any INCLUDE attributes"},
+		{name: "INCLUDE attributes with another projection", model: gsiIncludeWithKeysOnly{}, err: "uses the KEYS_ONLY Projection Type"},
+		{name: "index key without an attribute type", model: gsiKeyWithoutType{}, err: "missing an Attribute Type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := CreateTableInputFromStruct(tt.model)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := describeInput(input); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
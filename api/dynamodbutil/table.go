@@ -0,0 +1,160 @@
+package dynamodbutil
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// TableOptions - Table level settings a model can declare by implementing Tabler, zero values keep the defaults
+type TableOptions struct {
+	// TableName defaults to the name of the struct
+	TableName string
+	// StreamViewType enables a stream on the table, e.g. dynamodb.StreamViewTypeNewImage
+	StreamViewType string
+	// TTLAttribute enables Time to Live on the given (numeric, epoch seconds) attribute
+	TTLAttribute string
+	// BillingMode defaults to DefaultBillingMode, it is PROVISIONED whenever capacity units are set
+	BillingMode string
+	// ReadCapacityUnits and WriteCapacityUnits are used for the table and every global secondary index
+	ReadCapacityUnits  int64
+	WriteCapacityUnits int64
+	// PointInTimeRecovery enables continuous backups
+	PointInTimeRecovery bool
+}
+
+// Tabler - Implemented by models that declare their own TableOptions
+type Tabler interface {
+	TableOptions() TableOptions
+}
+
+// TableDefinition - Everything needed to create a table, TTL and point in time recovery are separate calls after creation
+type TableDefinition struct {
+	CreateTableInput             *db.CreateTableInput
+	UpdateTimeToLiveInput        *db.UpdateTimeToLiveInput
+	UpdateContinuousBackupsInput *db.UpdateContinuousBackupsInput
+}
+
+// TableOptionsFromStruct - Returns the options declared by s, or the defaults if s does not implement Tabler
+func TableOptionsFromStruct(s interface{}) TableOptions {
+	var options TableOptions
+	if t, ok := s.(Tabler); ok {
+		options = t.TableOptions()
+	}
+	if options.TableName == "" {
+		t := reflect.TypeOf(s)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		options.TableName = t.Name()
+	}
+	if options.BillingMode == "" {
+		options.BillingMode = DefaultBillingMode
+		if options.ReadCapacityUnits > 0 || options.WriteCapacityUnits > 0 {
+			options.BillingMode = db.BillingModeProvisioned
+		}
+	}
+	return options
+}
+
+// TableNameFromStruct - Returns the table name declared by s, defaulting to the name of the struct
+func TableNameFromStruct(s interface{}) string {
+	return TableOptionsFromStruct(s).TableName
+}
+
+// applyTableOptions - Sets the table name, billing and stream settings of the input
+func applyTableOptions(input *db.CreateTableInput, options TableOptions) error {
+	input.TableName = aws.String(options.TableName)
+	input.BillingMode = aws.String(options.BillingMode)
+	switch options.BillingMode {
+	case db.BillingModePayPerRequest:
+		if options.ReadCapacityUnits > 0 || options.WriteCapacityUnits > 0 {
+			return fmt.Errorf("Error: table %s uses %s billing but declares provisioned throughput", options.TableName, options.BillingMode)
+		}
+	case db.BillingModeProvisioned:
+		if options.ReadCapacityUnits <= 0 || options.WriteCapacityUnits <= 0 {
+			return fmt.Errorf("Error: table %s uses %s billing but does not declare read and write capacity units", options.TableName, options.BillingMode)
+		}
+		throughput := &db.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(options.ReadCapacityUnits),
+			WriteCapacityUnits: aws.Int64(options.WriteCapacityUnits),
+		}
+		input.ProvisionedThroughput = throughput
+		for _, gsi := range input.GlobalSecondaryIndexes {
+			gsi.ProvisionedThroughput = throughput
+		}
+	default:
+		return fmt.Errorf("Error: %s is not a valid Billing Mode", options.BillingMode)
+	}
+	if options.StreamViewType != "" {
+		input.StreamSpecification = &db.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: aws.String(options.StreamViewType),
+		}
+	}
+	return nil
+}
+
+// TableDefinitionFromStruct - Generates the CreateTableInput of s along with the TTL and point in time recovery updates it declares
+func TableDefinitionFromStruct(s interface{}) (*TableDefinition, error) {
+	input, err := CreateTableInputFromStruct(s)
+	if err != nil {
+		return nil, err
+	}
+	options := TableOptionsFromStruct(s)
+	def := &TableDefinition{CreateTableInput: input}
+	if options.TTLAttribute != "" {
+		def.UpdateTimeToLiveInput = &db.UpdateTimeToLiveInput{
+			TableName: aws.String(options.TableName),
+			TimeToLiveSpecification: &db.TimeToLiveSpecification{
+				AttributeName: aws.String(options.TTLAttribute),
+				Enabled:       aws.Bool(true),
+			},
+		}
+	}
+	if options.PointInTimeRecovery {
+		def.UpdateContinuousBackupsInput = &db.UpdateContinuousBackupsInput{
+			TableName: aws.String(options.TableName),
+			PointInTimeRecoverySpecification: &db.PointInTimeRecoverySpecification{
+				PointInTimeRecoveryEnabled: aws.Bool(true),
+			},
+		}
+	}
+	return def, nil
+}
+
+// CreateTableFromStruct - Creates the table described by s, see CreateTableFromDefinition
+func CreateTableFromStruct(ddbClient dynamodbiface.DynamoDBAPI, s interface{}) error {
+	def, err := TableDefinitionFromStruct(s)
+	if err != nil {
+		return err
+	}
+	return CreateTableFromDefinition(ddbClient, def)
+}
+
+// CreateTableFromDefinition - Creates the table, waits for it to become active and applies its TTL and point in time recovery settings
+func CreateTableFromDefinition(ddbClient dynamodbiface.DynamoDBAPI, def *TableDefinition) error {
+	if _, err := ddbClient.CreateTable(def.CreateTableInput); err != nil {
+		return err
+	}
+	if def.UpdateTimeToLiveInput == nil && def.UpdateContinuousBackupsInput == nil {
+		return nil
+	}
+	if err := ddbClient.WaitUntilTableExists(&db.DescribeTableInput{TableName: def.CreateTableInput.TableName}); err != nil {
+		return err
+	}
+	if def.UpdateTimeToLiveInput != nil {
+		if _, err := ddbClient.UpdateTimeToLive(def.UpdateTimeToLiveInput); err != nil {
+			return err
+		}
+	}
+	if def.UpdateContinuousBackupsInput != nil {
+		if _, err := ddbClient.UpdateContinuousBackups(def.UpdateContinuousBackupsInput); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package dynamodbutil
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type untagged struct {
+	ID string `at:"S" kt:"HASH"`
+}
+
+type provisioned struct {
+	ID    string `at:"S" kt:"HASH"`
+	Group string `at:"S" gsi:"GroupIndex,HASH"`
+}
+
+func (provisioned) TableOptions() TableOptions {
+	return TableOptions{TableName: "Provisioned", ReadCapacityUnits: 2, WriteCapacityUnits: 3}
+}
+
+type onDemandWithCapacity struct {
+	ID string `at:"S" kt:"HASH"`
+}
+
+func (onDemandWithCapacity) TableOptions() TableOptions {
+	return TableOptions{BillingMode: dynamodb.BillingModePayPerRequest, ReadCapacityUnits: 1, WriteCapacityUnits: 1}
+}
+
+type provisionedWithoutCapacity struct {
+	ID string `at:"S" kt:"HASH"`
+}
+
+func (provisionedWithoutCapacity) TableOptions() TableOptions {
+	return TableOptions{BillingMode: dynamodb.BillingModeProvisioned, ReadCapacityUnits: 1}
+}
+
+type invalidBilling struct {
+	ID string `at:"S" kt:"HASH"`
+}
+
+func (invalidBilling) TableOptions() TableOptions {
+	return TableOptions{BillingMode: "FREE"}
+}
+
+// everything - Declares every option, along with both kinds of index
+type everything struct {
+	ID      string `at:"S" kt:"HASH" gsi:"GroupIndex,RANGE"`
+	Date    string `at:"S" kt:"RANGE"`
+	Group   string `at:"S" gsi:"GroupIndex,HASH"`
+	Score   int64  `at:"N" lsi:"ScoreIndex,RANGE,KEYS_ONLY"`
+	Name    string `gsi:"GroupIndex,INCLUDE"`
+	Expires int64
+}
+
+func (everything) TableOptions() TableOptions {
+	return TableOptions{
+		TableName:           "Everything",
+		StreamViewType:      dynamodb.StreamViewTypeNewAndOldImages,
+		TTLAttribute:        "Expires",
+		BillingMode:         dynamodb.BillingModePayPerRequest,
+		PointInTimeRecovery: true,
+	}
+}
+
+func describeThroughput(t *dynamodb.ProvisionedThroughput) string {
+	return fmt.Sprintf("%d/%d", aws.Int64Value(t.ReadCapacityUnits), aws.Int64Value(t.WriteCapacityUnits))
+}
+
+// describeDefinition - Summarises how the options were applied to the definition
+func describeDefinition(def *TableDefinition) string {
+	in := def.CreateTableInput
+	parts := []string{aws.StringValue(in.TableName), aws.StringValue(in.BillingMode)}
+	if t := in.ProvisionedThroughput; t != nil {
+		parts = append(parts, "table "+describeThroughput(t))
+	}
+	for _, gsi := range in.GlobalSecondaryIndexes {
+		if t := gsi.ProvisionedThroughput; t != nil {
+			parts = append(parts, aws.StringValue(gsi.IndexName)+" "+describeThroughput(t))
+		}
+	}
+	if s := in.StreamSpecification; s != nil && aws.BoolValue(s.StreamEnabled) {
+		parts = append(parts, "stream "+aws.StringValue(s.StreamViewType))
+	}
+	if ttl := def.UpdateTimeToLiveInput; ttl != nil {
+		parts = append(parts, "ttl "+aws.StringValue(ttl.TableName)+"."+aws.StringValue(ttl.TimeToLiveSpecification.AttributeName))
+	}
+	if pitr := def.UpdateContinuousBackupsInput; pitr != nil && aws.BoolValue(pitr.PointInTimeRecoverySpecification.PointInTimeRecoveryEnabled) {
+		parts = append(parts, "pitr "+aws.StringValue(pitr.TableName))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func TestTableDefinitionFromStructOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		model interface{}
+		want  string
+		err   string
+	}{
+		{
+			name:  "defaults to the struct name and on demand billing",
+			model: untagged{},
+			want:  "untagged | PAY_PER_REQUEST",
+		},
+		{
+			name:  "capacity units switch to provisioned billing for the table and its global indexes",
+			model: provisioned{},
+			want:  "Provisioned | PROVISIONED | table 2/3 | GroupIndex 2/3",
+		},
+		{
+			name:  "stream, TTL and point in time recovery",
+			model: everything{},
+			want:  "Everything | PAY_PER_REQUEST | stream NEW_AND_OLD_IMAGES | ttl Everything.Expires | pitr Everything",
+		},
+		{name: "on demand billing with capacity units", model: onDemandWithCapacity{}, err: "declares provisioned throughput"},
+		{name: "provisioned billing without capacity units", model: provisionedWithoutCapacity{}, err: "does not declare read and write capacity units"},
+		{name: "invalid billing mode", model: invalidBilling{}, err: "FREE is not a valid Billing Mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := TableDefinitionFromStruct(tt.model)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := describeDefinition(def); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCloudFormation(t *testing.T) {
+	defs := []*TableDefinition{}
+	for _, m := range []interface{}{provisioned{}, everything{}} {
+		def, err := TableDefinitionFromStruct(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defs = append(defs, def)
+	}
+	var buf bytes.Buffer
+	if err := WriteCloudFormation(&buf, defs...); err != nil {
+		t.Fatal(err)
+	}
+	want := `Resources:
+  Provisioned:
+    Type: "AWS::DynamoDB::Table"
+    Properties:
+      TableName: Provisioned
+      AttributeDefinitions:
+        - AttributeName: ID
+          AttributeType: S
+        - AttributeName: Group
+          AttributeType: S
+      KeySchema:
+        - AttributeName: ID
+          KeyType: HASH
+      GlobalSecondaryIndexes:
+        - IndexName: GroupIndex
+          KeySchema:
+            - AttributeName: Group
+              KeyType: HASH
+          Projection:
+            ProjectionType: ALL
+          ProvisionedThroughput:
+            ReadCapacityUnits: 2
+            WriteCapacityUnits: 3
+      ProvisionedThroughput:
+        ReadCapacityUnits: 2
+        WriteCapacityUnits: 3
+  Everything:
+    Type: "AWS::DynamoDB::Table"
+    Properties:
+      TableName: Everything
+      BillingMode: PAY_PER_REQUEST
+      StreamSpecification:
+        StreamViewType: NEW_AND_OLD_IMAGES
+      AttributeDefinitions:
+        - AttributeName: ID
+          AttributeType: S
+        - AttributeName: Date
+          AttributeType: S
+        - AttributeName: Group
+          AttributeType: S
+        - AttributeName: Score
+          AttributeType: N
+      KeySchema:
+        - AttributeName: ID
+          KeyType: HASH
+        - AttributeName: Date
+          KeyType: RANGE
+      GlobalSecondaryIndexes:
+        - IndexName: GroupIndex
+          KeySchema:
+            - AttributeName: Group
+              KeyType: HASH
+            - AttributeName: ID
+              KeyType: RANGE
+          Projection:
+            ProjectionType: INCLUDE
+            NonKeyAttributes:
+              - Name
+      LocalSecondaryIndexes:
+        - IndexName: ScoreIndex
+          KeySchema:
+            - AttributeName: ID
+              KeyType: HASH
+            - AttributeName: Score
+              KeyType: RANGE
+          Projection:
+            ProjectionType: KEYS_ONLY
+      TimeToLiveSpecification:
+        AttributeName: Expires
+        Enabled: true
+      PointInTimeRecoverySpecification:
+        PointInTimeRecoveryEnabled: true
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
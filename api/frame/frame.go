@@ -0,0 +1,235 @@
+// Package frame lines up several time series on timestamp, e.g. on-chain metrics of several assets or on-chain data next to equity closes
+package frame
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	iex "github.com/goinvest/iexcloud/v2"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+)
+
+// FillRule - How a missing value is handled when a series has no point at a timestamp another series has
+type FillRule string
+
+const (
+	// FillForward - Repeat the last value of the series, values before its first point stay missing
+	FillForward FillRule = "ffill"
+	// FillDrop - Drop every timestamp at which any series is missing
+	FillDrop FillRule = "drop"
+	// FillZero - Use 0 for missing values
+	FillZero FillRule = "zero"
+)
+
+// Point - A single value of a series
+type Point struct {
+	Timestamp int64
+	Value     float64
+}
+
+// Series - Named points, Name becomes the column of the series in a Frame
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// FromPoints - Builds a series from the points returned by the glassnode client, null values are skipped so fill rules treat them as gaps
+// Multi-value points cannot be placed in a single column and return a MultiValueError
+func FromPoints(name string, points []*glassnode.Point) (Series, error) {
+	s := Series{Name: name, Points: make([]Point, 0, len(points))}
+	for _, p := range points {
+		if p.IsObject() {
+			return Series{}, &glassnode.MultiValueError{Timestamp: p.Timestamp}
+		}
+		if p.Value == nil {
+			continue
+		}
+		s.Points = append(s.Points, Point{Timestamp: p.Timestamp, Value: *p.Value})
+	}
+	return s, nil
+}
+
+// FromEntries - Builds a series from the entries returned by the glassnode client
+// Entries hold 0 where glassnode returned null, so missing values are not gaps, use FromPoints when that matters
+func FromEntries(name string, entries []*glassnode.MetricEntry) Series {
+	s := Series{Name: name, Points: make([]Point, len(entries))}
+	for i, e := range entries {
+		s.Points[i] = Point{Timestamp: e.Timestamp, Value: e.Value}
+	}
+	return s
+}
+
+// FromBatch - Builds one series per asset of a batch call, named after the asset and sorted by name
+// Like FromEntries, null values of the batch are 0 rather than gaps
+func FromBatch(results glassnode.BatchResults) []Series {
+	assets := make([]string, 0, len(results))
+	for asset := range results {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	series := make([]Series, len(assets))
+	for i, asset := range assets {
+		series[i] = FromEntries(asset, results[asset])
+	}
+	return series
+}
+
+// FromHistoricalCloses - Builds a series of daily closes from IEX (or Historical table) data points
+// Dates are read as midnight UTC, which is how glassnode timestamps daily points
+func FromHistoricalCloses(name string, historical []iex.HistoricalDataPoint) (Series, error) {
+	s := Series{Name: name, Points: make([]Point, len(historical))}
+	for i, h := range historical {
+		t, err := time.Parse("2006-01-02", h.Date)
+		if err != nil {
+			return Series{}, err
+		}
+		s.Points[i] = Point{Timestamp: t.Unix(), Value: h.Close}
+	}
+	return s, nil
+}
+
+// Frame - Several series joined on timestamp, one column per series and one row per timestamp
+type Frame struct {
+	columns    []string
+	timestamps []int64
+	// values[row][column], nil when missing
+	values [][]*float64
+}
+
+// Join - Joins the series on the union of their timestamps, filling gaps according to fill
+// Series names must be unique since they become the columns, when a series has several points with the same timestamp the last one is used
+func Join(fill FillRule, series ...Series) (*Frame, error) {
+	switch fill {
+	case FillForward, FillDrop, FillZero:
+	default:
+		return nil, fmt.Errorf("Error, '%s' is not a valid fill rule", fill)
+	}
+	names := make(map[string]bool, len(series))
+	for _, s := range series {
+		if names[s.Name] {
+			return nil, fmt.Errorf("Error, series '%s' given more than once", s.Name)
+		}
+		names[s.Name] = true
+	}
+	f := &Frame{columns: make([]string, len(series))}
+	lookup := make([]map[int64]float64, len(series))
+	seen := map[int64]bool{}
+	for i, s := range series {
+		f.columns[i] = s.Name
+		lookup[i] = make(map[int64]float64, len(s.Points))
+		for _, p := range s.Points {
+			lookup[i][p.Timestamp] = p.Value
+			if !seen[p.Timestamp] {
+				seen[p.Timestamp] = true
+				f.timestamps = append(f.timestamps, p.Timestamp)
+			}
+		}
+	}
+	sort.Slice(f.timestamps, func(i, j int) bool {
+		return f.timestamps[i] < f.timestamps[j]
+	})
+
+	last := make([]*float64, len(series))
+	timestamps := make([]int64, 0, len(f.timestamps))
+	for _, ts := range f.timestamps {
+		row := make([]*float64, len(series))
+		complete := true
+		for i := range series {
+			if v, ok := lookup[i][ts]; ok {
+				v := v
+				row[i] = &v
+				last[i] = &v
+				continue
+			}
+			switch fill {
+			case FillForward:
+				row[i] = last[i]
+			case FillZero:
+				zero := 0.0
+				row[i] = &zero
+			}
+			if row[i] == nil {
+				complete = false
+			}
+		}
+		if fill == FillDrop && !complete {
+			continue
+		}
+		timestamps = append(timestamps, ts)
+		f.values = append(f.values, row)
+	}
+	f.timestamps = timestamps
+	return f, nil
+}
+
+// Columns - Returns the column names in the order the series were given
+func (f *Frame) Columns() []string {
+	columns := make([]string, len(f.columns))
+	copy(columns, f.columns)
+	return columns
+}
+
+// Len - Returns the number of rows
+func (f *Frame) Len() int {
+	return len(f.timestamps)
+}
+
+// Timestamp - Returns the timestamp of the row
+func (f *Frame) Timestamp(row int) int64 {
+	return f.timestamps[row]
+}
+
+// Value - Returns the value of the column at the row, ok is false if it is missing
+func (f *Frame) Value(row int, column int) (value float64, ok bool) {
+	v := f.values[row][column]
+	if v == nil {
+		return 0, false
+	}
+	return *v, true
+}
+
+// WriteCSV - Writes a timestamp column followed by one column per series, missing values are left empty
+func (f *Frame) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"timestamp"}, f.columns...)); err != nil {
+		return err
+	}
+	for i, ts := range f.timestamps {
+		record := make([]string, len(f.columns)+1)
+		record[0] = strconv.FormatInt(ts, 10)
+		for j, v := range f.values[i] {
+			if v != nil {
+				record[j+1] = strconv.FormatFloat(*v, 'f', -1, 64)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type jsonRow struct {
+	Timestamp int64      `json:"t"`
+	Values    []*float64 `json:"v"`
+}
+
+type jsonFrame struct {
+	Columns []string  `json:"columns"`
+	Rows    []jsonRow `json:"rows"`
+}
+
+// MarshalJSON - Encodes the frame as {"columns": [...], "rows": [{"t": ..., "v": [...]}]}, missing values are null
+func (f *Frame) MarshalJSON() ([]byte, error) {
+	out := jsonFrame{Columns: f.columns, Rows: make([]jsonRow, len(f.timestamps))}
+	for i, ts := range f.timestamps {
+		out.Rows[i] = jsonRow{Timestamp: ts, Values: f.values[i]}
+	}
+	return json.Marshal(out)
+}
@@ -0,0 +1,77 @@
+package frame
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+)
+
+func points(values ...*float64) []*glassnode.Point {
+	ps := make([]*glassnode.Point, len(values))
+	for i, v := range values {
+		ps[i] = &glassnode.Point{Timestamp: int64(i), Value: v}
+	}
+	return ps
+}
+
+func f(v float64) *float64 {
+	return &v
+}
+
+func TestFromPointsNullIsGap(t *testing.T) {
+	a, err := FromPoints("a", points(f(1), nil, f(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := FromPoints("b", points(f(5), f(6), f(7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forward, err := Join(FillForward, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := forward.Value(1, 0); !ok || v != 1 {
+		t.Fatalf("got %v %v, want the null forward filled with 1", v, ok)
+	}
+	if v, ok := forward.Value(2, 0); !ok || v != 0 {
+		t.Fatalf("got %v %v, want a real 0 kept", v, ok)
+	}
+
+	drop, err := Join(FillDrop, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if drop.Len() != 2 || drop.Timestamp(1) != 2 {
+		t.Fatalf("got %d rows, want the row with the null dropped", drop.Len())
+	}
+
+	buf := &bytes.Buffer{}
+	outer, err := Join(FillZero, Series{Name: "a", Points: a.Points}, Series{Name: "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := outer.WriteCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "timestamp,a,c\n0,1,0\n2,0,0\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestFromPointsRejectsMultiValue(t *testing.T) {
+	_, err := FromPoints("ohlc", []*glassnode.Point{{Timestamp: 1, Object: []byte(`{"o":1}`)}})
+	if _, ok := err.(*glassnode.MultiValueError); !ok {
+		t.Fatalf("got error %v, want MultiValueError", err)
+	}
+}
+
+func TestJoinRejectsDuplicateNames(t *testing.T) {
+	a := Series{Name: "BTC", Points: []Point{{Timestamp: 1, Value: 1}}}
+	if _, err := Join(FillForward, a, a); err == nil {
+		t.Fatal("got no error, want duplicate series rejected")
+	}
+}
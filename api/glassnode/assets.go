@@ -1,10 +1,8 @@
 package glassnode
 
 import (
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/http"
+	"context"
+	"strings"
 )
 
 const (
@@ -12,16 +10,35 @@ const (
 	ETH = "ETH"
 )
 
+// CryptoAssets - Assets stored alongside equities, e.g. in the Historical table
+var CryptoAssets = []string{BTC, ETH}
+
+// CryptoSymbolSuffix - Crypto assets are stored under their asset followed by this suffix, e.g. BTC-USD, so they never collide with an equity ticker
+const CryptoSymbolSuffix = "-USD"
+
+// CryptoSymbol - Returns the symbol the asset is stored under alongside equities
+func CryptoSymbol(asset string) string {
+	return asset + CryptoSymbolSuffix
+}
+
+// CryptoAssetFromSymbol - Returns the asset of a symbol built by CryptoSymbol for one of CryptoAssets, any other symbol is an equity to retrieve from IEX
+func CryptoAssetFromSymbol(symbol string) (string, bool) {
+	if !strings.HasSuffix(symbol, CryptoSymbolSuffix) {
+		return "", false
+	}
+	asset := strings.TrimSuffix(symbol, CryptoSymbolSuffix)
+	for _, a := range CryptoAssets {
+		if a == asset {
+			return asset, true
+		}
+	}
+	return "", false
+}
+
 const (
-	assetAPIRoute        = "/v2/metrics/endpoints"
-	assetTableName       = "Assets"
 	defaultEndpointRoute = "/v1/metrics/indicators/net_unrealized_profit_loss"
 )
 
-type AssetResponse struct {
-	Assets []*Asset
-}
-
 type Asset struct {
 	Symbol          string   `json:"symbol"`
 	Name            string   `json:"name"`
@@ -32,9 +49,10 @@ type Asset struct {
 }
 
 type AssetService interface {
-	Get(options *AssetOptions) ([]*Asset, error)
+	Get(ctx context.Context, options *AssetOptions) ([]*Asset, error)
 }
 
+// AssetOptions - Route is the metric path whose supported assets are returned
 type AssetOptions struct {
 	Route string
 }
@@ -43,48 +61,21 @@ func DefaultAssetOptions() *AssetOptions {
 	return &AssetOptions{Route: defaultEndpointRoute}
 }
 
-func (o *AssetOptions) ToQueryString() string {
-	return fmt.Sprintf("%s", assetAPIRoute)
-}
-
 type assetServicer struct {
-	base *baseClient
+	catalog CatalogService
 }
 
-func newAssetService(base *baseClient) AssetService {
+func newAssetService(catalog CatalogService) AssetService {
 	return &assetServicer{
-		base: base,
+		catalog: catalog,
 	}
 }
 
-func (s *assetServicer) Get(options *AssetOptions) ([]*Asset, error) {
-	resp, err := s.base.call(options)
+// Get - Returns the assets supported by the route, looked up in the endpoint catalogue
+func (s *assetServicer) Get(ctx context.Context, options *AssetOptions) ([]*Asset, error) {
+	e, err := s.catalog.Endpoint(ctx, options.Route)
 	if err != nil {
 		return nil, err
 	}
-
-	ts, err := parseAssets(resp, options)
-	if err != nil {
-		return nil, err
-	}
-	return ts, nil
-}
-
-func parseAssets(resp *http.Response, options *AssetOptions) ([]*Asset, error) {
-	var target []map[string]interface{}
-	err := json.NewDecoder(resp.Body).Decode(target)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, route := range target {
-		if route["path"] == options.Route {
-			assetResponse, ok := route["assets"].(*AssetResponse)
-			if !ok {
-				return nil, errors.New("Error decoding asset response")
-			}
-			return assetResponse.Assets, nil
-		}
-	}
-	return nil, fmt.Errorf("Error decoding asset response, Route '%s' not found", options.Route)
+	return e.Assets, nil
 }
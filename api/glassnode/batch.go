@@ -0,0 +1,90 @@
+package glassnode
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultBatchWorkers - Number of assets fetched concurrently by the batch methods
+const DefaultBatchWorkers = 4
+
+// BatchResults - Entries returned by a batch call, keyed by asset
+type BatchResults map[string][]*MetricEntry
+
+// BatchErrors - Errors returned by a batch call, keyed by asset
+type BatchErrors map[string]error
+
+func (e BatchErrors) Error() string {
+	assets := make([]string, 0, len(e))
+	for asset := range e {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	msgs := make([]string, len(assets))
+	for i, asset := range assets {
+		msgs[i] = fmt.Sprintf("%s: %s", asset, strings.TrimSpace(e[asset].Error()))
+	}
+	return fmt.Sprintf("Error, batch failed for %d asset(s): %s\n", len(e), strings.Join(msgs, "; "))
+}
+
+// batchFunc - Fetches the entries for a single asset
+type batchFunc func(ctx context.Context, asset string) ([]*MetricEntry, error)
+
+type batchResult struct {
+	asset   string
+	entries []*MetricEntry
+	err     error
+}
+
+// runBatch - Calls fn for every asset using at most workers goroutines
+// Every asset ends up in exactly one of the returned results or errors, assets not started before ctx is done fail with ctx.Err()
+// The error is nil or a BatchErrors, results for the assets that succeeded are returned either way
+func runBatch(ctx context.Context, assets []string, workers int, fn batchFunc) (BatchResults, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(assets) {
+		workers = len(assets)
+	}
+	jobs := make(chan string)
+	// buffered so workers never block on a slow collector
+	out := make(chan batchResult, len(assets))
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for asset := range jobs {
+				if err := ctx.Err(); err != nil {
+					out <- batchResult{asset: asset, err: err}
+					continue
+				}
+				entries, err := fn(ctx, asset)
+				out <- batchResult{asset: asset, entries: entries, err: err}
+			}
+		}()
+	}
+	for _, asset := range assets {
+		jobs <- asset
+	}
+	close(jobs)
+	wg.Wait()
+	close(out)
+
+	results := BatchResults{}
+	errs := BatchErrors{}
+	for r := range out {
+		if r.err != nil {
+			errs[r.asset] = r.err
+			continue
+		}
+		results[r.asset] = r.entries
+	}
+	if len(errs) > 0 {
+		return results, errs
+	}
+	return results, nil
+}
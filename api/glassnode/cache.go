@@ -0,0 +1,190 @@
+package glassnode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCacheTTL - TTL of cached responses for requests without an interval, such as the endpoint catalogue
+const DefaultCacheTTL = time.Hour
+
+// Cache - Stores raw response bodies keyed by canonical query string, implementations must be safe for concurrent use
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// CacheStats - Number of requests answered from the cache and requests that had to be sent
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+type cacheCounter struct {
+	hits   uint64
+	misses uint64
+}
+
+func (c *cacheCounter) hit() {
+	atomic.AddUint64(&c.hits, 1)
+}
+
+func (c *cacheCounter) miss() {
+	atomic.AddUint64(&c.misses, 1)
+}
+
+func (c *cacheCounter) stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+	}
+}
+
+// cacheKey - Returns the query with its parameters sorted so equivalent requests share a key
+// The key is prefixed with the base URL and a hash of the API key, so clients sharing a cache never read each other's responses
+func (g *baseClient) cacheKey(query string) string {
+	sum := sha256.Sum256([]byte(g.apiKey))
+	prefix := strings.TrimRight(g.baseURL, "/") + "#" + hex.EncodeToString(sum[:8])
+	u, err := url.Parse(query)
+	if err != nil {
+		return prefix + query
+	}
+	if encoded := u.Query().Encode(); encoded != "" {
+		return prefix + u.Path + "?" + encoded
+	}
+	return prefix + u.Path
+}
+
+// cacheTTL - Returns how long a response stays fresh, based on the interval requested
+// Finer intervals get new points more often so they expire sooner
+func cacheTTL(query string) time.Duration {
+	u, err := url.Parse(query)
+	if err != nil {
+		return DefaultCacheTTL
+	}
+	switch Interval(u.Query().Get("i")) {
+	case Interval10m:
+		return 5 * time.Minute
+	case Interval1h:
+		return 30 * time.Minute
+	case Interval24h:
+		return time.Hour
+	case Interval1w:
+		return 6 * time.Hour
+	case Interval1month:
+		return 24 * time.Hour
+	default:
+		return DefaultCacheTTL
+	}
+}
+
+type memoryCacheItem struct {
+	value   []byte
+	expires time.Time
+}
+
+// MemoryCache - Cache kept in process memory, expired items are removed when they are next read
+type MemoryCache struct {
+	mu    sync.RWMutex
+	items map[string]memoryCacheItem
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		items: map[string]memoryCacheItem{},
+	}
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.RLock()
+	item, ok := c.items[key]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(item.expires) {
+		c.mu.Lock()
+		delete(c.items, key)
+		c.mu.Unlock()
+		return nil, false
+	}
+	return item.value, true
+}
+
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = memoryCacheItem{value: value, expires: time.Now().Add(ttl)}
+}
+
+// FileCache - Cache stored as one file per key in a directory, so it can be shared between processes and survive restarts
+type FileCache struct {
+	dir string
+}
+
+type fileCacheItem struct {
+	Expires time.Time `json:"expires"`
+	Value   []byte    `json:"value"`
+}
+
+// NewFileCache - Returns a cache stored in dir, creating the directory if needed
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *FileCache) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	item := fileCacheItem{}
+	if err := json.Unmarshal(data, &item); err != nil || time.Now().After(item.Expires) {
+		os.Remove(path)
+		return nil, false
+	}
+	return item.Value, true
+}
+
+func (c *FileCache) Set(key string, value []byte, ttl time.Duration) {
+	data, err := json.Marshal(fileCacheItem{Expires: time.Now().Add(ttl), Value: value})
+	if err != nil {
+		return
+	}
+	// write to a temporary file first so readers never see a partial item
+	tmp, err := ioutil.TempFile(c.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	os.Rename(tmp.Name(), c.path(key))
+}
+
+// isCacheable - Only metric and catalogue requests are cached
+func isCacheable(query string) bool {
+	return strings.HasPrefix(query, "/v1/metrics/") || strings.HasPrefix(query, catalogAPIRoute)
+}
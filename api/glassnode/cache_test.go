@@ -0,0 +1,45 @@
+package glassnode
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	a := newGlassNodeBaseClient("key-a", WithBaseURL("https://a.example/"))
+	query := "/v1/metrics/indicators/sopr?i=24h&a=BTC"
+	if got, want := a.cacheKey(query), a.cacheKey("/v1/metrics/indicators/sopr?a=BTC&i=24h"); got != want {
+		t.Fatalf("got %q and %q, want reordered parameters to share a key", got, want)
+	}
+	for name, other := range map[string]*baseClient{
+		"api key":  newGlassNodeBaseClient("key-b", WithBaseURL("https://a.example/")),
+		"base url": newGlassNodeBaseClient("key-a", WithBaseURL("https://b.example/")),
+	} {
+		if a.cacheKey(query) == other.cacheKey(query) {
+			t.Fatalf("got the same key for a different %s, want %q kept apart", name, a.cacheKey(query))
+		}
+	}
+	if got := a.cacheKey(query); got != newGlassNodeBaseClient("key-a", WithBaseURL("https://a.example")).cacheKey(query) {
+		t.Fatalf("got %q, want a trailing slash on the base URL ignored", got)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	tests := []struct {
+		query string
+		want  time.Duration
+	}{
+		{"/v1/metrics/indicators/sopr?a=BTC&i=10m", 5 * time.Minute},
+		{"/v1/metrics/indicators/sopr?a=BTC&i=1h", 30 * time.Minute},
+		{"/v1/metrics/indicators/sopr?a=BTC&i=24h", time.Hour},
+		{"/v1/metrics/indicators/sopr?a=BTC&i=1w", 6 * time.Hour},
+		{"/v1/metrics/indicators/sopr?a=BTC&i=1month", 24 * time.Hour},
+		{"/v2/metrics/endpoints", DefaultCacheTTL},
+		{"/v1/metrics/indicators/sopr?a=BTC&i=2d", DefaultCacheTTL},
+	}
+	for _, tt := range tests {
+		if got := cacheTTL(tt.query); got != tt.want {
+			t.Errorf("cacheTTL(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
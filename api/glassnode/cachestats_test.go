@@ -0,0 +1,57 @@
+package glassnode_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode/glassnodetest"
+)
+
+func soprOptions() *glassnode.MetricOptions {
+	o := glassnode.DefaultMetricOptions(glassnodetest.SoprPath)
+	o.Asset = glassnode.BTC
+	return o
+}
+
+func TestCacheStats(t *testing.T) {
+	s := glassnodetest.NewServer(nil)
+	defer s.Close()
+	other := glassnodetest.NewServer(nil)
+	defer other.Close()
+	cache := glassnode.NewMemoryCache()
+
+	c := s.Client(glassnode.WithCache(cache))
+	for i := 0; i < 3; i++ {
+		if _, err := c.Metrics.Get(context.Background(), soprOptions()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := c.CacheStats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Fatalf("got %+v, want 2 hits and 1 miss", stats)
+	}
+
+	// clients sharing the cache only read responses fetched with the same base URL and API key
+	elsewhere := other.Client(glassnode.WithCache(cache))
+	if _, err := elsewhere.Metrics.Get(context.Background(), soprOptions()); err != nil {
+		t.Fatal(err)
+	}
+	if stats := elsewhere.CacheStats(); stats.Hits != 0 || stats.Misses != 1 || other.Requests(glassnodetest.SoprPath) != 1 {
+		t.Fatalf("got %+v after %d requests, want a miss sent to the other server", stats, other.Requests(glassnodetest.SoprPath))
+	}
+	wrongKey := glassnode.NewGlassNodeClient("wrong", glassnode.WithBaseURL(s.URL), glassnode.WithHTTPClient(s.Server.Client()), glassnode.WithCache(cache))
+	if _, err := wrongKey.Metrics.Get(context.Background(), soprOptions()); err == nil {
+		t.Fatal("got no error, want the wrong API key rejected instead of served from the cache")
+	}
+	if stats := wrongKey.CacheStats(); stats.Hits != 0 || stats.Misses != 1 {
+		t.Fatalf("got %+v, want 1 miss", stats)
+	}
+
+	uncached := s.Client()
+	if _, err := uncached.Metrics.Get(context.Background(), soprOptions()); err != nil {
+		t.Fatal(err)
+	}
+	if stats := uncached.CacheStats(); stats != (glassnode.CacheStats{}) {
+		t.Fatalf("got %+v, want no hits or misses without a cache", stats)
+	}
+}
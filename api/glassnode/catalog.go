@@ -0,0 +1,186 @@
+package glassnode
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	catalogAPIRoute = "/v2/metrics/endpoints"
+	// DefaultCatalogTTL - How long the endpoint catalogue is kept in memory before it is fetched again
+	DefaultCatalogTTL = 24 * time.Hour
+)
+
+// Endpoint - A metric path along with everything it supports
+type Endpoint struct {
+	Path        string     `json:"path"`
+	Tier        int        `json:"tier"`
+	Assets      []*Asset   `json:"assets"`
+	Currencies  []Currency `json:"currencies"`
+	Resolutions []Interval `json:"resolutions"`
+	Formats     []Format   `json:"formats"`
+}
+
+// SupportsAsset - Returns true if the endpoint can be queried for the given asset symbol
+func (e *Endpoint) SupportsAsset(symbol string) bool {
+	for _, a := range e.Assets {
+		if a.Symbol == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsResolution - Returns true if the endpoint can be queried at the given interval
+func (e *Endpoint) SupportsResolution(interval Interval) bool {
+	for _, r := range e.Resolutions {
+		if r == interval {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsCurrency - Returns true if the endpoint can return values in the given currency
+func (e *Endpoint) SupportsCurrency(currency Currency) bool {
+	for _, c := range e.Currencies {
+		if c == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsFormat - Returns true if the endpoint can respond in the given format
+func (e *Endpoint) SupportsFormat(format Format) bool {
+	for _, f := range e.Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// CatalogService - Lists every metric endpoint available from the API, the list is cached in memory
+type CatalogService interface {
+	List(ctx context.Context) ([]*Endpoint, error)
+	Endpoint(ctx context.Context, path string) (*Endpoint, error)
+	Validate(ctx context.Context, options *MetricOptions) error
+	Refresh(ctx context.Context) error
+}
+
+type catalogOptions struct{}
+
+func (o catalogOptions) ToQueryString() string {
+	return catalogAPIRoute
+}
+
+type catalogServicer struct {
+	base      *baseClient
+	ttl       time.Duration
+	mu        sync.RWMutex
+	endpoints []*Endpoint
+	byPath    map[string]*Endpoint
+	fetchedAt time.Time
+}
+
+func newCatalogService(base *baseClient) CatalogService {
+	return &catalogServicer{
+		base: base,
+		ttl:  DefaultCatalogTTL,
+	}
+}
+
+// List - Returns every endpoint sorted by path, fetching the catalogue if it is not cached or has expired
+func (s *catalogServicer) List(ctx context.Context) ([]*Endpoint, error) {
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	endpoints := make([]*Endpoint, len(s.endpoints))
+	copy(endpoints, s.endpoints)
+	return endpoints, nil
+}
+
+// Endpoint - Returns the endpoint for the given metric path
+func (s *catalogServicer) Endpoint(ctx context.Context, path string) (*Endpoint, error) {
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.byPath[path]
+	if !ok {
+		return nil, &RouteNotRecognizedError{Route: path}
+	}
+	return e, nil
+}
+
+// Validate - Checks the options against the catalogue without sending the metric request
+func (s *catalogServicer) Validate(ctx context.Context, options *MetricOptions) error {
+	e, err := s.Endpoint(ctx, options.Path)
+	if err != nil {
+		return err
+	}
+	if options.Asset != "" && len(e.Assets) > 0 && !e.SupportsAsset(options.Asset) {
+		return &UnsupportedOptionError{Path: options.Path, Option: "asset", Value: options.Asset}
+	}
+	if options.Interval != "" && len(e.Resolutions) > 0 && !e.SupportsResolution(options.Interval) {
+		return &UnsupportedOptionError{Path: options.Path, Option: "interval", Value: string(options.Interval)}
+	}
+	if options.Currency != "" && len(e.Currencies) > 0 && !e.SupportsCurrency(options.Currency) {
+		return &UnsupportedOptionError{Path: options.Path, Option: "currency", Value: string(options.Currency)}
+	}
+	if options.Format != "" && len(e.Formats) > 0 && !e.SupportsFormat(options.Format) {
+		return &UnsupportedOptionError{Path: options.Path, Option: "format", Value: string(options.Format)}
+	}
+	return nil
+}
+
+// Refresh - Fetches the catalogue even if the cached copy has not expired
+func (s *catalogServicer) Refresh(ctx context.Context) error {
+	body, err := s.base.call(ctx, catalogOptions{})
+	if err != nil {
+		return err
+	}
+	endpoints, err := parseCatalog(body)
+	if err != nil {
+		return err
+	}
+	byPath := make(map[string]*Endpoint, len(endpoints))
+	for _, e := range endpoints {
+		byPath[e.Path] = e
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints = endpoints
+	s.byPath = byPath
+	s.fetchedAt = time.Now()
+	return nil
+}
+
+func (s *catalogServicer) load(ctx context.Context) error {
+	s.mu.RLock()
+	fresh := s.byPath != nil && time.Since(s.fetchedAt) < s.ttl
+	s.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+	return s.Refresh(ctx)
+}
+
+func parseCatalog(body []byte) ([]*Endpoint, error) {
+	target := []*Endpoint{}
+	err := json.Unmarshal(body, &target)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(target, func(i, j int) bool {
+		return target[i].Path < target[j].Path
+	})
+	return target, nil
+}
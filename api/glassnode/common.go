@@ -1,45 +1,22 @@
 package glassnode
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
+	"strings"
+	"time"
 )
 
+// DefaultTimeout - Upper bound in seconds for a single request, pass a context with a deadline for anything tighter
 const DefaultTimeout = 60
 
 type RequestOptions interface {
 	ToQueryString() string
 }
 
-type ResultError struct {
-	Result interface{}
-	Error  error
-}
-
-// CollectResults - Returns an error if one occurred, otherwise returns an array of results
-func CollectResults(ch chan ResultError, l int, target interface{}) error {
-	t := reflect.ValueOf(target).Elem()
-	for i := 0; i < l; i++ {
-		tmp := <-ch
-		err := tmp.Error
-		if err != nil {
-			return err
-		}
-		// append the array contained in result to target
-		result := tmp.Result
-		items := reflect.ValueOf(result)
-		if items.Kind() == reflect.Slice {
-			for j := 0; j < items.Len(); j++ {
-				item := items.Index(j)
-				if item.Kind() == reflect.Ptr || item.Kind() == reflect.Struct {
-					t.Set(reflect.Append(t, item))
-				}
-			}
-		}
-	}
-	return nil
-}
-
 type RouteNotRecognizedError struct {
 	Route string
 }
@@ -55,3 +32,112 @@ type OptionParseError struct {
 func (o OptionParseError) Error() string {
 	return fmt.Sprintf("Error, unable to parse options to the desired type of '%s'\n", o.DesiredType)
 }
+
+type UnsupportedOptionError struct {
+	Path   string
+	Option string
+	Value  string
+}
+
+func (u UnsupportedOptionError) Error() string {
+	return fmt.Sprintf("Error, %s '%s' is not supported by '%s'\n", u.Option, u.Value, u.Path)
+}
+
+type MultiValueError struct {
+	Timestamp int64
+}
+
+func (m MultiValueError) Error() string {
+	return fmt.Sprintf("Error, point at %d holds several values, use GetPoints to retrieve it\n", m.Timestamp)
+}
+
+type SingleValueError struct {
+	Timestamp int64
+}
+
+func (s SingleValueError) Error() string {
+	return fmt.Sprintf("Error, point at %d holds a single value\n", s.Timestamp)
+}
+
+type InvalidRangeError struct {
+	Since time.Time
+	Until time.Time
+}
+
+func (r InvalidRangeError) Error() string {
+	return fmt.Sprintf("Error, since '%s' must be before until '%s'\n", r.Since.Format(time.RFC3339), r.Until.Format(time.RFC3339))
+}
+
+// maxErrorMessageLength - Non JSON error bodies longer than this are truncated
+const maxErrorMessageLength = 256
+
+// APIError - Returned for any response from the Glassnode API with a non 2xx status
+type APIError struct {
+	StatusCode int
+	Endpoint   string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("Error, request to '%s' failed with status %d", e.Endpoint, e.StatusCode)
+	}
+	return fmt.Sprintf("Error, request to '%s' failed with status %d: %s", e.Endpoint, e.StatusCode, e.Message)
+}
+
+// newAPIError - Builds an APIError, taking the message from the body's "message" or "error" field if it is JSON and the raw body otherwise
+func newAPIError(statusCode int, endpoint string, body []byte) *APIError {
+	message := ""
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		for _, key := range []string{"message", "error", "msg"} {
+			if m, ok := payload[key].(string); ok && m != "" {
+				message = m
+				break
+			}
+		}
+	}
+	if message == "" {
+		message = strings.TrimSpace(string(body))
+		if len(message) > maxErrorMessageLength {
+			message = message[:maxErrorMessageLength] + "..."
+		}
+	}
+	return &APIError{StatusCode: statusCode, Endpoint: endpoint, Message: message}
+}
+
+// endpointFromQuery - Strips the query parameters from a request path so it can be reported without leaking options or keys
+func endpointFromQuery(query string) string {
+	if i := strings.Index(query, "?"); i >= 0 {
+		return query[:i]
+	}
+	return query
+}
+
+// IsUnauthorized - Returns true if err was caused by a missing or invalid API key
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsTierRestricted - Returns true if err was caused by requesting a metric or resolution the API key's tier cannot access
+func IsTierRestricted(err error) bool {
+	return hasStatus(err, http.StatusForbidden, http.StatusPaymentRequired)
+}
+
+// IsRateLimited - Returns true if err was caused by exceeding the API's rate limit
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+func hasStatus(err error, statusCodes ...int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range statusCodes {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
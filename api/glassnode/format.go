@@ -0,0 +1,116 @@
+package glassnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gocarina/gocsv"
+)
+
+type Format string
+
+const (
+	FormatJSON    Format = "JSON"
+	FormatCSV     Format = "CSV"
+	FormatDefault Format = FormatJSON
+)
+
+const (
+	csvTimestampColumn = "timestamp"
+	csvValueColumn     = "value"
+)
+
+// parsePoints - Decodes a metric response in the given format into points, an empty format is treated as JSON
+func parsePoints(body []byte, format Format) ([]*Point, error) {
+	switch format {
+	case FormatJSON, "":
+		return parseJSONPoints(body)
+	case FormatCSV:
+		return parseCSVPoints(body)
+	default:
+		return nil, &UnsupportedOptionError{Option: "format", Value: string(format)}
+	}
+}
+
+func parseJSONPoints(body []byte) ([]*Point, error) {
+	target := []*Point{}
+	err := json.Unmarshal(body, &target)
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// parseCSVPoints - A CSV with a single value column decodes to single value points, any other columns become the values of multi-value points
+func parseCSVPoints(body []byte) ([]*Point, error) {
+	rows, err := gocsv.CSVToMaps(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	target := make([]*Point, len(rows))
+	for i, row := range rows {
+		ts, err := parseCSVTimestamp(row[csvTimestampColumn])
+		if err != nil {
+			return nil, err
+		}
+		p := &Point{Timestamp: ts}
+		if value, ok := row[csvValueColumn]; ok && len(row) == 2 {
+			if value != "" {
+				v, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return nil, err
+				}
+				p.Value = &v
+			}
+			target[i] = p
+			continue
+		}
+		object := map[string]interface{}{}
+		for column, value := range row {
+			if column == csvTimestampColumn {
+				continue
+			}
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				object[column] = v
+			} else {
+				object[column] = value
+			}
+		}
+		if p.Object, err = json.Marshal(object); err != nil {
+			return nil, err
+		}
+		target[i] = p
+	}
+	return target, nil
+}
+
+// parseTimestamp - Decodes a JSON timestamp given either as a number or a string
+func parseTimestamp(raw json.RawMessage) (int64, error) {
+	if len(raw) == 0 {
+		return 0, nil
+	}
+	var ts int64
+	if err := json.Unmarshal(raw, &ts); err == nil {
+		return ts, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return 0, fmt.Errorf("Error, unable to parse timestamp '%s'", raw)
+	}
+	return parseCSVTimestamp(s)
+}
+
+// parseCSVTimestamp - CSV timestamps are RFC3339 by default, or unix seconds when requested
+func parseCSVTimestamp(value string) (int64, error) {
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return ts, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("Error, unable to parse CSV timestamp '%s'", value)
+	}
+	return t.Unix(), nil
+}
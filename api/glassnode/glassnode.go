@@ -1,88 +1,232 @@
 package glassnode
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 )
 
 type GlassNodeRouteName string
 
 const (
-	GlassNodeBaseURL                    = "https://api.glassnode.com"
-	NuplRouteName    GlassNodeRouteName = "nupl"
+	GlassNodeBaseURL                            = "https://api.glassnode.com"
+	APIKeyHeader                                = "X-Api-Key"
+	NuplRouteName            GlassNodeRouteName = "nupl"
+	SoprRouteName            GlassNodeRouteName = "sopr"
+	MvrvRouteName            GlassNodeRouteName = "mvrv"
+	ExchangeBalanceRouteName GlassNodeRouteName = "exchange_balance"
+	ActiveAddressesRouteName GlassNodeRouteName = "active_addresses"
+	MarketCapRouteName       GlassNodeRouteName = "marketcap"
+	RealizedCapRouteName     GlassNodeRouteName = "realized_cap"
+	PriceOHLCRouteName       GlassNodeRouteName = "price_ohlc"
+	HodlWavesRouteName       GlassNodeRouteName = "hodl_waves"
 )
 
+// defaultMetricRoutes - Metrics registered on every new client
+var defaultMetricRoutes = map[GlassNodeRouteName]string{
+	NuplRouteName:            nuplRoute,
+	SoprRouteName:            soprRoute,
+	MvrvRouteName:            mvrvRoute,
+	ExchangeBalanceRouteName: exchangeBalanceRoute,
+	ActiveAddressesRouteName: activeAddressesRoute,
+	MarketCapRouteName:       marketCapRoute,
+	RealizedCapRouteName:     realizedCapRoute,
+	PriceOHLCRouteName:       priceOHLCRoute,
+	HodlWavesRouteName:       hodlWavesRoute,
+}
+
 type GlassNodeClient struct {
 	base                    *baseClient
 	Assets                  AssetService
+	Catalog                 CatalogService
+	Metrics                 MetricService
 	NetUnrealizedProfitLoss NetUnrealizedProfitLossService
+	routes                  map[GlassNodeRouteName]string
+	routesMu                sync.RWMutex
 }
 
-func NewGlassNodeClient(apiKey string) *GlassNodeClient {
-	base := newGlassNodeBaseClient(apiKey)
+// NewGlassNodeClient - Builds a client for the Glassnode API, by default requests are limited to DefaultRateLimit per second
+// and retried up to DefaultMaxRetries times, use the ClientOption functions to change this
+func NewGlassNodeClient(apiKey string, opts ...ClientOption) *GlassNodeClient {
+	base := newGlassNodeBaseClient(apiKey, opts...)
+	catalog := newCatalogService(base)
+	metrics := newMetricService(base, catalog)
+	routes := make(map[GlassNodeRouteName]string, len(defaultMetricRoutes))
+	for name, path := range defaultMetricRoutes {
+		routes[name] = path
+	}
 	return &GlassNodeClient{
 		base:                    base,
-		Assets:                  newAssetService(base),
-		NetUnrealizedProfitLoss: newNetUnrealizedProfitLossService(base),
+		Assets:                  newAssetService(catalog),
+		Catalog:                 catalog,
+		Metrics:                 metrics,
+		NetUnrealizedProfitLoss: newNetUnrealizedProfitLossService(metrics),
+		routes:                  routes,
 	}
 }
 
-func (c *GlassNodeClient) BatchCall(routeName GlassNodeRouteName, assets []string, target interface{}, options RequestOptions) error {
-	switch routeName {
-	case NuplRouteName:
-		o, ok := options.(*NetUnrealizedProfitLossOptions)
-		if !ok {
-			return OptionParseError{DesiredType: reflect.TypeOf(&NetUnrealizedProfitLossOptions{})}
-		}
-		return c.GetBatchNupl(assets, target, o)
+// RegisterMetric - Makes the metric at path available to BatchCall under the given route name
+func (c *GlassNodeClient) RegisterMetric(routeName GlassNodeRouteName, path string) {
+	c.routesMu.Lock()
+	defer c.routesMu.Unlock()
+	c.routes[routeName] = path
+}
 
-	default:
-		return &RouteNotRecognizedError{Route: string(routeName)}
-	}
+// MetricPath - Returns the path registered for the route name, if any
+func (c *GlassNodeClient) MetricPath(routeName GlassNodeRouteName) (string, bool) {
+	c.routesMu.RLock()
+	defer c.routesMu.RUnlock()
+	path, ok := c.routes[routeName]
+	return path, ok
 }
 
-func (c *GlassNodeClient) GetBatchNupl(assets []string, target interface{}, options *NetUnrealizedProfitLossOptions) error {
-	ch := make(chan ResultError)
-	for _, asset := range assets {
-		options = &NetUnrealizedProfitLossOptions{
-			Asset:    asset,
-			Interval: options.Interval,
-			Since:    options.Since,
-			Until:    options.Until,
-		}
-		go func(o *NetUnrealizedProfitLossOptions) {
-			c.NetUnrealizedProfitLoss.GetBatch(o, ch)
-		}(&NetUnrealizedProfitLossOptions{
-			Asset:    asset,
-			Interval: options.Interval,
-			Since:    options.Since,
-			Until:    options.Until})
+// BatchCall - Retrieves the registered metric for every asset, see GetBatchMetric
+// options may be *MetricOptions for any registered route, or *NetUnrealizedProfitLossOptions for NuplRouteName
+func (c *GlassNodeClient) BatchCall(ctx context.Context, routeName GlassNodeRouteName, assets []string, options RequestOptions) (BatchResults, error) {
+	path, ok := c.MetricPath(routeName)
+	if !ok {
+		return nil, &RouteNotRecognizedError{Route: string(routeName)}
 	}
-	t := target.([]*NetUnrealizedProfitLossEntry)
-	err := CollectResults(ch, len(assets), &t)
-	if err != nil {
-		return err
+	switch o := options.(type) {
+	case *NetUnrealizedProfitLossOptions:
+		if routeName != NuplRouteName {
+			return nil, OptionParseError{DesiredType: reflect.TypeOf(&MetricOptions{})}
+		}
+		return c.GetBatchNupl(ctx, assets, o)
+	case *MetricOptions:
+		mo := *o
+		mo.Path = path
+		return c.GetBatchMetric(ctx, assets, &mo)
+	default:
+		return nil, OptionParseError{DesiredType: reflect.TypeOf(&MetricOptions{})}
 	}
-	return nil
+}
+
+// GetBatchMetric - Retrieves the metric described by options for every asset, fetching at most the configured number of assets at a time
+// Results are keyed by asset, if any asset fails the error is a BatchErrors and the results still hold every asset that succeeded
+func (c *GlassNodeClient) GetBatchMetric(ctx context.Context, assets []string, options *MetricOptions) (BatchResults, error) {
+	return runBatch(ctx, assets, c.base.batchWorkers, func(ctx context.Context, asset string) ([]*MetricEntry, error) {
+		return c.Metrics.Get(ctx, options.withAsset(asset))
+	})
+}
+
+func (c *GlassNodeClient) GetBatchNupl(ctx context.Context, assets []string, options *NetUnrealizedProfitLossOptions) (BatchResults, error) {
+	return c.GetBatchMetric(ctx, assets, options.toMetricOptions())
+}
+
+// CacheStats - Returns the cache hits and misses since the client was built, both are 0 if no cache is configured
+func (c *GlassNodeClient) CacheStats() CacheStats {
+	return c.base.cacheCounter.stats()
 }
 
 type baseClient struct {
 	httpClient *http.Client
 	apiKey     string
+	baseURL    string
+	// headerAuth - Send the API key in the X-Api-Key header instead of the query string
+	headerAuth   bool
+	limiter      *rateLimiter
+	retry        retryPolicy
+	batchWorkers int
+	// validateWithCatalog - Check metric options against the endpoint catalogue before sending them
+	validateWithCatalog bool
+	cache               Cache
+	cacheCounter        *cacheCounter
 }
 
-func (g baseClient) call(options RequestOptions) (*http.Response, error) {
-	url := GlassNodeBaseURL + options.ToQueryString() + fmt.Sprintf("&api_key=%s", g.apiKey)
-	return g.httpClient.Get(url)
+// call - Performs the request described by options and returns the response body, the request is aborted once ctx is cancelled or its deadline passes
+// Every attempt waits on the shared rate limiter, responses with a 429 or 5xx status are retried according to the retry policy
+// Any non 2xx response that is not retried is returned as an *APIError
+// Successful responses are served from and stored in the cache when one is configured
+func (g *baseClient) call(ctx context.Context, options RequestOptions) ([]byte, error) {
+	query := options.ToQueryString()
+	var key string
+	if g.cache != nil && isCacheable(query) {
+		key = g.cacheKey(query)
+		if body, ok := g.cache.Get(key); ok {
+			g.cacheCounter.hit()
+			return body, nil
+		}
+		g.cacheCounter.miss()
+	}
+	url := g.url(query)
+	for attempt := 0; ; attempt++ {
+		if err := g.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if g.headerAuth {
+			req.Header.Set(APIKeyHeader, g.apiKey)
+		}
+		resp, err := g.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if g.retry.shouldRetry(resp.StatusCode) && attempt < g.retry.maxRetries {
+			delay := g.retry.delay(attempt, resp)
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+			if err := sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, newAPIError(resp.StatusCode, endpointFromQuery(query), body)
+		}
+		if key != "" {
+			g.cache.Set(key, body, cacheTTL(query))
+		}
+		return body, nil
+	}
+}
+
+// url - Returns the full URL for the query, including the API key unless it is sent as a header
+func (g *baseClient) url(query string) string {
+	url := strings.TrimRight(g.baseURL, "/") + query
+	if g.headerAuth {
+		return url
+	}
+	sep := "?"
+	if strings.Contains(query, "?") {
+		sep = "&"
+	}
+	return url + fmt.Sprintf("%sapi_key=%s", sep, g.apiKey)
 }
 
-func newGlassNodeBaseClient(apiKey string) *baseClient {
-	return &baseClient{
+func newGlassNodeBaseClient(apiKey string, opts ...ClientOption) *baseClient {
+	base := &baseClient{
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout * time.Second,
 		},
-		apiKey: apiKey,
+		apiKey:       apiKey,
+		baseURL:      GlassNodeBaseURL,
+		limiter:      newRateLimiter(DefaultRateLimit, DefaultRateLimitBurst),
+		batchWorkers: DefaultBatchWorkers,
+		cacheCounter: &cacheCounter{},
+		retry: retryPolicy{
+			maxRetries:        DefaultMaxRetries,
+			initialBackoff:    DefaultInitialBackoff,
+			maxBackoff:        DefaultMaxBackoff,
+			respectRetryAfter: true,
+		},
+	}
+	for _, opt := range opts {
+		opt(base)
 	}
+	return base
 }
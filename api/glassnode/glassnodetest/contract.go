@@ -0,0 +1,350 @@
+package glassnodetest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+)
+
+// RunContractTests - Runs the real client against a Server and checks it behaves like it does against the API
+// opts are passed to every client built, so the suite can also be run against a client configured the way it is used in production
+// Call it from a test:
+//
+//	func TestGlassnodeContract(t *testing.T) {
+//		glassnodetest.RunContractTests(t)
+//	}
+func RunContractTests(t *testing.T, opts ...glassnode.ClientOption) {
+	for _, tc := range contractTests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewServer(nil)
+			defer s.Close()
+			tc.run(t, s, s.Client(opts...))
+		})
+	}
+}
+
+type contractTest struct {
+	name string
+	run  func(t *testing.T, s *Server, c *glassnode.GlassNodeClient)
+}
+
+var contractTests = []contractTest{
+	{"GetReturnsSeries", testGetReturnsSeries},
+	{"GetFiltersRange", testGetFiltersRange},
+	{"GetRejectsInvertedRange", testGetRejectsInvertedRange},
+	{"GetSplitsLongRanges", testGetSplitsLongRanges},
+	{"CSVMatchesJSON", testCSVMatchesJSON},
+	{"HumanizedTimestamps", testHumanizedTimestamps},
+	{"MultiValuePoints", testMultiValuePoints},
+	{"Catalog", testCatalog},
+	{"CatalogValidation", testCatalogValidation},
+	{"HeaderAuth", testHeaderAuth},
+	{"Unauthorized", testUnauthorized},
+	{"TierRestricted", testTierRestricted},
+	{"RetriesRateLimit", testRetriesRateLimit},
+	{"RateLimitedAfterRetries", testRateLimitedAfterRetries},
+	{"RetriesServerError", testRetriesServerError},
+	{"MalformedJSON", testMalformedJSON},
+	{"ContextDeadline", testContextDeadline},
+	{"BatchKeyedByAsset", testBatchKeyedByAsset},
+	{"Cache", testCache},
+}
+
+func metricOptions(path string) *glassnode.MetricOptions {
+	o := glassnode.DefaultMetricOptions(path)
+	o.Asset = glassnode.BTC
+	return o
+}
+
+func assertEntries(t *testing.T, got []*glassnode.MetricEntry, want []*glassnode.Point) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Timestamp != want[i].Timestamp || got[i].Value != *want[i].Value {
+			t.Fatalf("entry %d = %+v, want {%d %v}", i, *got[i], want[i].Timestamp, *want[i].Value)
+		}
+	}
+}
+
+func testGetReturnsSeries(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	got, err := c.Metrics.Get(context.Background(), metricOptions(SoprPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEntries(t, got, s.Fixtures.Points(SoprPath, glassnode.BTC))
+
+	nupl, err := c.NetUnrealizedProfitLoss.Get(context.Background(), glassnode.DefaultNetUnrealizedProfitLossOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEntries(t, nupl, s.Fixtures.Points(NuplPath, glassnode.BTC))
+}
+
+func testGetFiltersRange(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	o := metricOptions(SoprPath)
+	o.Since = Start.Add(5 * 24 * time.Hour)
+	o.Until = Start.Add(9 * 24 * time.Hour)
+	got, err := c.Metrics.Get(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEntries(t, got, s.Fixtures.Points(SoprPath, glassnode.BTC)[5:10])
+}
+
+func testGetRejectsInvertedRange(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	o := metricOptions(SoprPath)
+	o.Since = Start.Add(24 * time.Hour)
+	o.Until = Start
+	_, err := c.Metrics.Get(context.Background(), o)
+	var rangeErr *glassnode.InvalidRangeError
+	if !errors.As(err, &rangeErr) {
+		t.Fatalf("got error %v, want InvalidRangeError", err)
+	}
+	if n := s.Requests(AnyPath); n != 0 {
+		t.Fatalf("got %d requests, want 0", n)
+	}
+}
+
+func testGetSplitsLongRanges(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	o := metricOptions(HourlyPath)
+	o.Interval = glassnode.Interval1h
+	o.Since = Start
+	o.Until = Start.Add(time.Duration(HourlyPoints-1) * time.Hour)
+	got, err := c.Metrics.Get(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := s.Requests(HourlyPath); n < 2 {
+		t.Fatalf("got %d requests, want the range split into several", n)
+	}
+	assertEntries(t, got, s.Fixtures.Points(HourlyPath, glassnode.BTC))
+}
+
+func testCSVMatchesJSON(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	o := metricOptions(NuplPath)
+	o.Format = glassnode.FormatCSV
+	got, err := c.Metrics.Get(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEntries(t, got, s.Fixtures.Points(NuplPath, glassnode.BTC))
+
+	o = metricOptions(OHLCPath)
+	o.Format = glassnode.FormatCSV
+	points, err := c.Metrics.GetPoints(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := glassnode.OHLC{}
+	if err := s.Fixtures.Points(OHLCPath, glassnode.BTC)[0].Decode(&want); err != nil {
+		t.Fatal(err)
+	}
+	ohlc := glassnode.OHLC{}
+	if err := points[0].Decode(&ohlc); err != nil {
+		t.Fatal(err)
+	}
+	if ohlc != want {
+		t.Fatalf("got %+v, want %+v", ohlc, want)
+	}
+}
+
+func testHumanizedTimestamps(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	for _, format := range []glassnode.Format{glassnode.FormatJSON, glassnode.FormatCSV} {
+		o := metricOptions(SoprPath)
+		o.Format = format
+		o.TimestampFormat = glassnode.TimestampFormatHumanized
+		got, err := c.Metrics.Get(context.Background(), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEntries(t, got, s.Fixtures.Points(SoprPath, glassnode.BTC))
+	}
+}
+
+func testMultiValuePoints(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	points, err := c.Metrics.GetPoints(context.Background(), metricOptions(OHLCPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != DailyPoints || !points[0].IsObject() {
+		t.Fatalf("got %d points, want %d multi-value points", len(points), DailyPoints)
+	}
+	m, err := points[0].Map()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["h"] <= m["l"] {
+		t.Fatalf("got %v, want high above low", m)
+	}
+	var multi *glassnode.MultiValueError
+	if _, err := c.Metrics.Get(context.Background(), metricOptions(OHLCPath)); !errors.As(err, &multi) {
+		t.Fatalf("got error %v, want MultiValueError", err)
+	}
+}
+
+func testCatalog(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	endpoints, err := c.Catalog.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != len(s.Fixtures.Endpoints) {
+		t.Fatalf("got %d endpoints, want %d", len(endpoints), len(s.Fixtures.Endpoints))
+	}
+	assets, err := c.Assets.Get(context.Background(), &glassnode.AssetOptions{Route: HourlyPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 1 || assets[0].Symbol != glassnode.BTC {
+		t.Fatalf("got %d assets, want only BTC", len(assets))
+	}
+	if _, err := c.Catalog.Endpoint(context.Background(), NuplPath); err != nil {
+		t.Fatal(err)
+	}
+	// the catalogue is cached after the first request
+	if n := s.Requests("/v2/metrics/endpoints"); n != 1 {
+		t.Fatalf("got %d catalogue requests, want 1", n)
+	}
+}
+
+func testCatalogValidation(t *testing.T, s *Server, _ *glassnode.GlassNodeClient) {
+	c := s.Client(glassnode.WithCatalogValidation())
+	o := metricOptions(OHLCPath)
+	o.Interval = glassnode.Interval1h
+	_, err := c.Metrics.GetPoints(context.Background(), o)
+	var unsupported *glassnode.UnsupportedOptionError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("got error %v, want UnsupportedOptionError", err)
+	}
+	if n := s.Requests(OHLCPath); n != 0 {
+		t.Fatalf("got %d metric requests, want 0", n)
+	}
+}
+
+func testHeaderAuth(t *testing.T, s *Server, _ *glassnode.GlassNodeClient) {
+	for _, header := range []bool{true, false} {
+		opts := []glassnode.ClientOption{}
+		if header {
+			opts = append(opts, glassnode.WithAPIKeyHeader())
+		}
+		before := s.Requests(SoprPath)
+		if _, err := s.Client(opts...).Metrics.Get(context.Background(), metricOptions(SoprPath)); err != nil {
+			t.Fatal(err)
+		}
+		received := s.Received(SoprPath)[before:]
+		if len(received) == 0 {
+			t.Fatal("got no requests")
+		}
+		for _, r := range received {
+			inHeader, inQuery := r.Header.Get(glassnode.APIKeyHeader), r.URL.Query().Get("api_key")
+			// the key must only travel in the header in header mode, so it stays out of URLs and access logs
+			if header && (inHeader != APIKey || inQuery != "") {
+				t.Fatalf("got header %q and api_key %q, want the key in the %s header only", inHeader, inQuery, glassnode.APIKeyHeader)
+			}
+			if !header && (inHeader != "" || inQuery != APIKey) {
+				t.Fatalf("got header %q and api_key %q, want the key in the api_key parameter only", inHeader, inQuery)
+			}
+		}
+	}
+}
+
+func testUnauthorized(t *testing.T, s *Server, _ *glassnode.GlassNodeClient) {
+	c := glassnode.NewGlassNodeClient("wrong-key", glassnode.WithBaseURL(s.URL))
+	_, err := c.Metrics.Get(context.Background(), metricOptions(SoprPath))
+	if !glassnode.IsUnauthorized(err) {
+		t.Fatalf("got error %v, want unauthorized", err)
+	}
+}
+
+func testTierRestricted(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	_, err := c.Metrics.Get(context.Background(), metricOptions(RestrictedPath))
+	if !glassnode.IsTierRestricted(err) {
+		t.Fatalf("got error %v, want tier restricted", err)
+	}
+	var apiErr *glassnode.APIError
+	if !errors.As(err, &apiErr) || apiErr.Endpoint != RestrictedPath || apiErr.Message == "" {
+		t.Fatalf("got error %v, want an APIError for %s with a message", err, RestrictedPath)
+	}
+}
+
+func testRetriesRateLimit(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	f := RateLimitFault(0)
+	f.Times = 2
+	s.Inject(SoprPath, f)
+	if _, err := c.Metrics.Get(context.Background(), metricOptions(SoprPath)); err != nil {
+		t.Fatal(err)
+	}
+	if n := s.Requests(SoprPath); n != 3 {
+		t.Fatalf("got %d requests, want 3", n)
+	}
+}
+
+func testRateLimitedAfterRetries(t *testing.T, s *Server, _ *glassnode.GlassNodeClient) {
+	c := s.Client(glassnode.WithMaxRetries(1))
+	s.Inject(SoprPath, RateLimitFault(0))
+	_, err := c.Metrics.Get(context.Background(), metricOptions(SoprPath))
+	if !glassnode.IsRateLimited(err) {
+		t.Fatalf("got error %v, want rate limited", err)
+	}
+	if n := s.Requests(SoprPath); n != 2 {
+		t.Fatalf("got %d requests, want 2", n)
+	}
+}
+
+func testRetriesServerError(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	f := StatusFault(http.StatusInternalServerError)
+	f.Times = 1
+	s.Inject(SoprPath, f)
+	if _, err := c.Metrics.Get(context.Background(), metricOptions(SoprPath)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testMalformedJSON(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	s.Inject(SoprPath, MalformedJSONFault())
+	if _, err := c.Metrics.Get(context.Background(), metricOptions(SoprPath)); err == nil {
+		t.Fatal("got no error, want a decode error")
+	}
+}
+
+func testContextDeadline(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	s.Inject(SoprPath, SlowFault(time.Second))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := c.Metrics.Get(ctx, metricOptions(SoprPath))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want deadline exceeded", err)
+	}
+}
+
+func testBatchKeyedByAsset(t *testing.T, s *Server, c *glassnode.GlassNodeClient) {
+	results, err := c.BatchCall(context.Background(), glassnode.SoprRouteName, []string{glassnode.BTC, glassnode.ETH, "DOGE"}, glassnode.DefaultMetricOptions(""))
+	batchErrs, ok := err.(glassnode.BatchErrors)
+	if !ok || len(batchErrs) != 1 || batchErrs["DOGE"] == nil {
+		t.Fatalf("got error %v, want a single error for DOGE", err)
+	}
+	for _, asset := range []string{glassnode.BTC, glassnode.ETH} {
+		assertEntries(t, results[asset], s.Fixtures.Points(SoprPath, asset))
+	}
+}
+
+func testCache(t *testing.T, s *Server, _ *glassnode.GlassNodeClient) {
+	c := s.Client(glassnode.WithCache(glassnode.NewMemoryCache()))
+	for i := 0; i < 3; i++ {
+		if _, err := c.Metrics.Get(context.Background(), metricOptions(SoprPath)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := s.Requests(SoprPath); n != 1 {
+		t.Fatalf("got %d requests, want 1", n)
+	}
+	if stats := c.CacheStats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Fatalf("got %+v, want 2 hits and 1 miss", stats)
+	}
+}
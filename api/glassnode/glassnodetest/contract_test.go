@@ -0,0 +1,15 @@
+package glassnodetest
+
+import (
+	"testing"
+
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+)
+
+func TestContract(t *testing.T) {
+	RunContractTests(t)
+}
+
+func TestContractAPIKeyHeader(t *testing.T) {
+	RunContractTests(t, glassnode.WithAPIKeyHeader())
+}
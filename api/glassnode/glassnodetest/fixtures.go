@@ -0,0 +1,127 @@
+package glassnodetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+)
+
+const (
+	NuplPath      = "/v1/metrics/indicators/net_unrealized_profit_loss"
+	SoprPath      = "/v1/metrics/indicators/sopr"
+	MarketCapPath = "/v1/metrics/market/marketcap_usd"
+	// RealizedCapPath - Realized cap fixtures stay below market cap, so the MVRV Z-score is positive
+	RealizedCapPath = "/v1/metrics/market/marketcap_realized_usd"
+	OHLCPath        = "/v1/metrics/market/price_usd_ohlc"
+	// HourlyPath - A 1h series long enough that requesting all of it is split into several requests
+	HourlyPath = "/v1/metrics/test/hourly"
+	// RestrictedPath - Listed in the catalogue for tier 3 only, requests to it fail with a 403
+	RestrictedPath = "/v1/metrics/test/restricted"
+)
+
+// DailyPoints - Number of points in each daily fixture series
+const DailyPoints = 30
+
+// HourlyPoints - Number of points in the hourly fixture series, more than glassnode.MaxPointsPerRequest
+const HourlyPoints = glassnode.MaxPointsPerRequest + 500
+
+// Start - Timestamp of the first point of every fixture series
+var Start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Fixtures - Data served by a Server
+type Fixtures struct {
+	// Endpoints - Served from /v2/metrics/endpoints
+	Endpoints []*glassnode.Endpoint
+	// Series - Points served for each metric path and asset, sorted by timestamp
+	Series map[string]map[string][]*glassnode.Point
+}
+
+// Points - Returns the fixture points for the path and asset
+func (f *Fixtures) Points(path string, asset string) []*glassnode.Point {
+	return f.Series[path][asset]
+}
+
+// Endpoint - Returns the catalogue entry for the path, or nil if there is none
+func (f *Fixtures) Endpoint(path string) *glassnode.Endpoint {
+	for _, e := range f.Endpoints {
+		if e.Path == path {
+			return e
+		}
+	}
+	return nil
+}
+
+// DefaultFixtures - Daily NUPL, SOPR, market cap, realized cap and OHLC series for BTC and ETH, plus an hourly BTC series and a tier restricted endpoint
+func DefaultFixtures() *Fixtures {
+	assets := []string{glassnode.BTC, glassnode.ETH}
+	f := &Fixtures{
+		Series: map[string]map[string][]*glassnode.Point{},
+	}
+	values := map[string]func(j int, i int) float64{
+		// NUPL starts in capitulation and climbs through several regimes
+		NuplPath:        func(j, i int) float64 { return float64(j+1) * (float64(i) - 10) / 40 },
+		SoprPath:        func(j, i int) float64 { return 1 + float64(i%5-2)/100 },
+		MarketCapPath:   func(j, i int) float64 { return float64(j+1) * (1e9 + float64(i)*1e7) },
+		RealizedCapPath: func(j, i int) float64 { return float64(j+1) * (8e8 + float64(i)*5e6) },
+	}
+	for _, path := range []string{NuplPath, SoprPath, MarketCapPath, RealizedCapPath} {
+		f.Series[path] = map[string][]*glassnode.Point{}
+		for j, asset := range assets {
+			j, value := j, values[path]
+			f.Series[path][asset] = valuePoints(DailyPoints, 24*time.Hour, func(i int) float64 {
+				return value(j, i)
+			})
+		}
+		f.Endpoints = append(f.Endpoints, newEndpoint(path, 1, assets, glassnode.Interval24h, glassnode.Interval1h))
+	}
+	f.Series[OHLCPath] = map[string][]*glassnode.Point{}
+	for j, asset := range assets {
+		f.Series[OHLCPath][asset] = ohlcPoints(DailyPoints, float64(1000*(j+1)))
+	}
+	f.Endpoints = append(f.Endpoints, newEndpoint(OHLCPath, 1, assets, glassnode.Interval24h))
+
+	f.Series[HourlyPath] = map[string][]*glassnode.Point{
+		glassnode.BTC: valuePoints(HourlyPoints, time.Hour, func(i int) float64 { return float64(i) }),
+	}
+	f.Endpoints = append(f.Endpoints, newEndpoint(HourlyPath, 1, []string{glassnode.BTC}, glassnode.Interval1h))
+	f.Endpoints = append(f.Endpoints, newEndpoint(RestrictedPath, 3, assets, glassnode.Interval24h))
+	return f
+}
+
+func newEndpoint(path string, tier int, assets []string, resolutions ...glassnode.Interval) *glassnode.Endpoint {
+	e := &glassnode.Endpoint{
+		Path:        path,
+		Tier:        tier,
+		Currencies:  []glassnode.Currency{glassnode.CurrencyNative, glassnode.CurrencyUSD},
+		Resolutions: resolutions,
+		Formats:     []glassnode.Format{glassnode.FormatJSON, glassnode.FormatCSV},
+	}
+	for _, a := range assets {
+		e.Assets = append(e.Assets, &glassnode.Asset{Symbol: a, Name: a})
+	}
+	return e
+}
+
+func valuePoints(n int, step time.Duration, value func(i int) float64) []*glassnode.Point {
+	points := make([]*glassnode.Point, n)
+	for i := range points {
+		v := value(i)
+		points[i] = &glassnode.Point{Timestamp: Start.Add(time.Duration(i) * step).Unix(), Value: &v}
+	}
+	return points
+}
+
+func ohlcPoints(n int, base float64) []*glassnode.Point {
+	points := make([]*glassnode.Point, n)
+	for i := range points {
+		open := base + float64(i)
+		o, err := json.Marshal(glassnode.OHLC{Open: open, High: open + 2, Low: open - 1, Close: open + 1})
+		if err != nil {
+			panic(fmt.Sprintf("glassnodetest: unable to build OHLC fixture: %v", err))
+		}
+		points[i] = &glassnode.Point{Timestamp: Start.Add(time.Duration(i) * 24 * time.Hour).Unix(), Object: o}
+	}
+	return points
+}
@@ -0,0 +1,322 @@
+// Package glassnodetest provides a fake Glassnode API for testing code that uses the glassnode client without network access
+package glassnodetest
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+)
+
+// APIKey - Key accepted by servers built with NewServer
+const APIKey = "glassnodetest-key"
+
+// AnyPath - Faults injected for AnyPath apply to every request
+const AnyPath = "*"
+
+// Fault - Replaces the normal response to a request
+type Fault struct {
+	// StatusCode - Responds with this status and Body instead of the fixture data
+	StatusCode int
+	Body       string
+	// RetryAfter - Value of the Retry-After header sent with StatusCode
+	RetryAfter string
+	// Malformed - Responds with a 200 and a truncated JSON body
+	Malformed bool
+	// Delay - Waits this long, or until the request is cancelled, before responding
+	Delay time.Duration
+	// Times - Number of requests the fault applies to, 0 means every request
+	Times int
+}
+
+// StatusFault - Responds with the given status code
+func StatusFault(statusCode int) Fault {
+	return Fault{StatusCode: statusCode, Body: fmt.Sprintf(`{"message":"%s"}`, http.StatusText(statusCode))}
+}
+
+// RateLimitFault - Responds with a 429 asking the client to retry after the given number of seconds
+func RateLimitFault(retryAfterSeconds int) Fault {
+	f := StatusFault(http.StatusTooManyRequests)
+	f.RetryAfter = strconv.Itoa(retryAfterSeconds)
+	return f
+}
+
+// MalformedJSONFault - Responds with a 200 whose body is not valid JSON
+func MalformedJSONFault() Fault {
+	return Fault{Malformed: true}
+}
+
+// SlowFault - Waits for d before responding normally
+func SlowFault(d time.Duration) Fault {
+	return Fault{Delay: d}
+}
+
+// Server - Fake Glassnode API serving fixture data from /v1/metrics/* and /v2/metrics/endpoints
+type Server struct {
+	*httptest.Server
+	Fixtures *Fixtures
+
+	mu       sync.Mutex
+	faults   map[string][]*Fault
+	requests map[string][]*http.Request
+}
+
+// NewServer - Starts a server for the given fixtures, DefaultFixtures are used if fixtures is nil
+// The caller must call Close once done
+func NewServer(fixtures *Fixtures) *Server {
+	if fixtures == nil {
+		fixtures = DefaultFixtures()
+	}
+	s := &Server{
+		Fixtures: fixtures,
+		faults:   map[string][]*Fault{},
+		requests: map[string][]*http.Request{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Client - Returns a client pointed at the server, without rate limiting and with short backoffs so tests stay fast
+// opts are applied last and may override any of these
+func (s *Server) Client(opts ...glassnode.ClientOption) *glassnode.GlassNodeClient {
+	defaults := []glassnode.ClientOption{
+		glassnode.WithBaseURL(s.URL),
+		glassnode.WithHTTPClient(s.Server.Client()),
+		glassnode.WithRateLimit(0, 0),
+		glassnode.WithBackoff(time.Millisecond, 10*time.Millisecond),
+	}
+	return glassnode.NewGlassNodeClient(APIKey, append(defaults, opts...)...)
+}
+
+// Inject - Applies the fault to requests for path, or to every request if path is AnyPath
+// Faults for the same path are applied in the order they were injected
+func (s *Server) Inject(path string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[path] = append(s.faults[path], &f)
+}
+
+// ClearFaults - Removes every injected fault
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = map[string][]*Fault{}
+}
+
+// Requests - Returns the number of requests received for path, or for every path if path is AnyPath
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if path == AnyPath {
+		total := 0
+		for _, requests := range s.requests {
+			total += len(requests)
+		}
+		return total
+	}
+	return len(s.requests[path])
+}
+
+// Received - Returns the requests received for path in order, so tests can check their URL and headers
+func (s *Server) Received(path string) []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := make([]*http.Request, len(s.requests[path]))
+	copy(requests, s.requests[path])
+	return requests
+}
+
+// nextFault - Records the request and returns the fault to apply to it, consuming one use of it
+func (s *Server) nextFault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := r.URL.Path
+	s.requests[path] = append(s.requests[path], r.Clone(context.Background()))
+	for _, key := range []string{path, AnyPath} {
+		faults := s.faults[key]
+		if len(faults) == 0 {
+			continue
+		}
+		f := faults[0]
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults[key] = faults[1:]
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if f := s.nextFault(r); f != nil {
+		if f.Delay > 0 {
+			select {
+			case <-time.After(f.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if f.Malformed {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"t":1577836800,"v":`))
+			return
+		}
+		if f.StatusCode != 0 {
+			if f.RetryAfter != "" {
+				w.Header().Set("Retry-After", f.RetryAfter)
+			}
+			w.WriteHeader(f.StatusCode)
+			w.Write([]byte(f.Body))
+			return
+		}
+	}
+	key := r.Header.Get(glassnode.APIKeyHeader)
+	if key == "" {
+		key = r.URL.Query().Get("api_key")
+	}
+	if key != APIKey {
+		writeError(w, http.StatusUnauthorized, "invalid api key")
+		return
+	}
+	if r.URL.Path == "/v2/metrics/endpoints" {
+		writeJSON(w, s.Fixtures.Endpoints)
+		return
+	}
+	s.handleMetric(w, r)
+}
+
+func (s *Server) handleMetric(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	endpoint := s.Fixtures.Endpoint(r.URL.Path)
+	series, ok := s.Fixtures.Series[r.URL.Path]
+	if !ok {
+		if endpoint != nil && endpoint.Tier > 1 {
+			writeError(w, http.StatusForbidden, "metric not available for your tier")
+			return
+		}
+		writeError(w, http.StatusNotFound, "metric not found")
+		return
+	}
+	points, ok := series[q.Get("a")]
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("asset '%s' not supported", q.Get("a")))
+		return
+	}
+	if i := glassnode.Interval(q.Get("i")); i != "" && endpoint != nil && !endpoint.SupportsResolution(i) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("resolution '%s' not supported", i))
+		return
+	}
+	since, until, err := parseRange(q.Get("s"), q.Get("u"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	selected := []*glassnode.Point{}
+	for _, p := range points {
+		if p.Timestamp >= since && p.Timestamp <= until {
+			selected = append(selected, p)
+		}
+	}
+	humanized := glassnode.TimestampFormat(q.Get("timestamp_format")) == glassnode.TimestampFormatHumanized
+	if glassnode.Format(q.Get("f")) == glassnode.FormatCSV {
+		writeCSV(w, selected, humanized)
+		return
+	}
+	if !humanized {
+		writeJSON(w, selected)
+		return
+	}
+	out := make([]map[string]interface{}, len(selected))
+	for i, p := range selected {
+		out[i] = map[string]interface{}{"t": formatTimestamp(p.Timestamp, true)}
+		if p.IsObject() {
+			out[i]["o"] = p.Object
+		} else {
+			out[i]["v"] = p.Value
+		}
+	}
+	writeJSON(w, out)
+}
+
+// parseRange - Both ends are inclusive, like the real API
+func parseRange(s string, u string) (int64, int64, error) {
+	since, until := int64(0), int64(1<<62)
+	var err error
+	if s != "" {
+		if since, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid since '%s'", s)
+		}
+	}
+	if u != "" {
+		if until, err = strconv.ParseInt(u, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid until '%s'", u)
+		}
+	}
+	return since, until, nil
+}
+
+func formatTimestamp(ts int64, humanized bool) string {
+	if humanized {
+		return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+	}
+	return strconv.FormatInt(ts, 10)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+// writeCSV - Single value points are written as timestamp,value, multi-value points get one column per value
+func writeCSV(w http.ResponseWriter, points []*glassnode.Point, humanized bool) {
+	w.Header().Set("Content-Type", "text/csv")
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+	columns := []string{}
+	if len(points) > 0 && points[0].IsObject() {
+		m := map[string]interface{}{}
+		points[0].Decode(&m)
+		for k := range m {
+			columns = append(columns, k)
+		}
+		sort.Strings(columns)
+	}
+	if len(columns) == 0 {
+		cw.Write([]string{"timestamp", "value"})
+		for _, p := range points {
+			value := ""
+			if p.Value != nil {
+				value = strconv.FormatFloat(*p.Value, 'f', -1, 64)
+			}
+			cw.Write([]string{formatTimestamp(p.Timestamp, humanized), value})
+		}
+		return
+	}
+	cw.Write(append([]string{"timestamp"}, columns...))
+	for _, p := range points {
+		m := map[string]interface{}{}
+		p.Decode(&m)
+		row := []string{formatTimestamp(p.Timestamp, humanized)}
+		for _, c := range columns {
+			row = append(row, strings.TrimSpace(fmt.Sprint(m[c])))
+		}
+		cw.Write(row)
+	}
+}
@@ -0,0 +1,174 @@
+package glassnode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	soprRoute            = "/v1/metrics/indicators/sopr"
+	mvrvRoute            = "/v1/metrics/market/mvrv"
+	exchangeBalanceRoute = "/v1/metrics/distribution/balance_exchanges"
+	activeAddressesRoute = "/v1/metrics/addresses/active_count"
+	marketCapRoute       = "/v1/metrics/market/marketcap_usd"
+	realizedCapRoute     = "/v1/metrics/market/marketcap_realized_usd"
+	priceOHLCRoute       = "/v1/metrics/market/price_usd_ohlc"
+	hodlWavesRoute       = "/v1/metrics/supply/hodl_waves"
+)
+
+// MetricEntry - A single {t, v} point returned by a /v1/metrics endpoint
+type MetricEntry struct {
+	Timestamp int64   `json:"t"`
+	Value     float64 `json:"v"`
+}
+
+// UnmarshalJSON - Accepts timestamps both as unix seconds and, when requested with TimestampFormatHumanized, as RFC3339 strings
+func (e *MetricEntry) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Timestamp json.RawMessage `json:"t"`
+		Value     float64         `json:"v"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	ts, err := parseTimestamp(raw.Timestamp)
+	if err != nil {
+		return err
+	}
+	e.Timestamp = ts
+	e.Value = raw.Value
+	return nil
+}
+
+type MetricService interface {
+	Get(ctx context.Context, options *MetricOptions) ([]*MetricEntry, error)
+	GetPoints(ctx context.Context, options *MetricOptions) ([]*Point, error)
+}
+
+// MetricOptions - Options for any /v1/metrics endpoint, Path is the full route of the metric (e.g. /v1/metrics/indicators/sopr)
+type MetricOptions struct {
+	Path            string
+	Asset           string
+	Interval        Interval
+	Since           time.Time
+	Until           time.Time
+	Format          Format
+	Currency        Currency
+	TimestampFormat TimestampFormat
+}
+
+func DefaultMetricOptions(path string) *MetricOptions {
+	return &MetricOptions{
+		Path:     path,
+		Asset:    BTC,
+		Interval: IntervalDefault,
+		Format:   FormatDefault,
+	}
+}
+
+func (o *MetricOptions) ToQueryString() string {
+	qs := fmt.Sprintf("%s?a=%s&i=%s", o.Path, o.Asset, o.Interval)
+	if !o.Since.IsZero() {
+		qs += fmt.Sprintf("&s=%d", o.Since.Unix())
+	}
+	if !o.Until.IsZero() {
+		qs += fmt.Sprintf("&u=%d", o.Until.Unix())
+	}
+	if o.Format != "" {
+		qs += fmt.Sprintf("&f=%s", o.Format)
+	}
+	if o.Currency != "" {
+		qs += fmt.Sprintf("&c=%s", o.Currency)
+	}
+	if o.TimestampFormat != "" {
+		qs += fmt.Sprintf("&timestamp_format=%s", o.TimestampFormat)
+	}
+	return qs
+}
+
+// withAsset - Returns a copy of the options for the given asset
+func (o *MetricOptions) withAsset(asset string) *MetricOptions {
+	c := *o
+	c.Asset = asset
+	return &c
+}
+
+// withRange - Returns a copy of the options restricted to the given range
+func (o *MetricOptions) withRange(r timeRange) *MetricOptions {
+	c := *o
+	c.Since = r.Since
+	c.Until = r.Until
+	return &c
+}
+
+type metricServicer struct {
+	base    *baseClient
+	catalog CatalogService
+}
+
+func newMetricService(base *baseClient, catalog CatalogService) MetricService {
+	return &metricServicer{
+		base:    base,
+		catalog: catalog,
+	}
+}
+
+// Get - Retrieves a single value metric, see GetPoints for metrics returning several values per point
+func (m metricServicer) Get(ctx context.Context, options *MetricOptions) ([]*MetricEntry, error) {
+	points, err := m.GetPoints(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	return pointsToEntries(points)
+}
+
+// GetPoints - Retrieves the metric, ranges too long for a single request are split up and the results merged by timestamp
+// Options are validated before any request is sent, including against the endpoint catalogue if the client was built WithCatalogValidation
+func (m metricServicer) GetPoints(ctx context.Context, options *MetricOptions) ([]*Point, error) {
+	if err := m.validate(ctx, options); err != nil {
+		return nil, err
+	}
+	chunks := splitRange(options.Since, options.Until, options.Interval)
+	if len(chunks) == 1 {
+		return m.get(ctx, options)
+	}
+	results := make([][]*Point, 0, len(chunks))
+	for _, r := range chunks {
+		points, err := m.get(ctx, options.withRange(r))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, points)
+	}
+	return mergePoints(results), nil
+}
+
+func (m metricServicer) validate(ctx context.Context, options *MetricOptions) error {
+	if options.Path == "" {
+		return &RouteNotRecognizedError{Route: options.Path}
+	}
+	if err := validateParams(options.Path, options.Interval, options.Currency, options.Format, options.TimestampFormat); err != nil {
+		return err
+	}
+	if err := validateRange(options.Since, options.Until); err != nil {
+		return err
+	}
+	if m.base.validateWithCatalog {
+		return m.catalog.Validate(ctx, options)
+	}
+	return nil
+}
+
+func (m metricServicer) get(ctx context.Context, options *MetricOptions) ([]*Point, error) {
+	body, err := m.base.call(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	ps, err := parsePoints(body, options.Format)
+	if err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
@@ -1,37 +1,29 @@
 package glassnode
 
 import (
-	"encoding/json"
-	"fmt"
-	"net/http"
+	"context"
 	"sync"
+	"time"
 )
 
-type Interval string
-
 const (
-	nuplRoute                = "/v1/metrics/indicators/net_unrealized_profit_loss"
-	nuplTableName            = "NetUnrealizedProfitLoss"
-	Interval24h     Interval = "24h"
-	Interval1h      Interval = "1h"
-	IntervalDefault Interval = Interval24h
+	nuplRoute = "/v1/metrics/indicators/net_unrealized_profit_loss"
 )
 
-type NetUnrealizedProfitLossEntry struct {
-	Timestamp int64   `json:"t"`
-	Value     float64 `json:"v"`
-}
+type NetUnrealizedProfitLossEntry = MetricEntry
 
 type NetUnrealizedProfitLossService interface {
-	Get(options *NetUnrealizedProfitLossOptions) ([]*NetUnrealizedProfitLossEntry, error)
-	GetBatch(options *NetUnrealizedProfitLossOptions, ch chan<- ResultError)
+	Get(ctx context.Context, options *NetUnrealizedProfitLossOptions) ([]*NetUnrealizedProfitLossEntry, error)
 }
 
 type NetUnrealizedProfitLossOptions struct {
-	Asset    string
-	Interval Interval
-	Since    *int
-	Until    *int
+	Asset           string
+	Interval        Interval
+	Since           time.Time
+	Until           time.Time
+	Format          Format
+	Currency        Currency
+	TimestampFormat TimestampFormat
 	sync.RWMutex
 }
 
@@ -39,60 +31,40 @@ func DefaultNetUnrealizedProfitLossOptions() *NetUnrealizedProfitLossOptions {
 	return &NetUnrealizedProfitLossOptions{
 		Asset:    BTC,
 		Interval: IntervalDefault,
-		Since:    nil,
-		Until:    nil,
+		Format:   FormatDefault,
 	}
 }
 
 func (o *NetUnrealizedProfitLossOptions) ToQueryString() string {
-	o.Lock()
-	defer o.Unlock()
-	qs := fmt.Sprintf("%s?a=%s&i=%s", nuplRoute, o.Asset, o.Interval)
-	if o.Since != nil {
-		qs += fmt.Sprintf("&s=%s", *o.Since)
-	}
-	if o.Until != nil {
-		qs += fmt.Sprintf("&u=%s", *o.Until)
-	}
-	return qs
-}
-
-type netUnrealizedProfitLossServicer struct {
-	base *baseClient
+	return o.toMetricOptions().ToQueryString()
 }
 
-func newNetUnrealizedProfitLossService(base *baseClient) NetUnrealizedProfitLossService {
-	return &netUnrealizedProfitLossServicer{
-		base: base,
+// toMetricOptions - Converts the options to the equivalent options for the generic metric service
+func (o *NetUnrealizedProfitLossOptions) toMetricOptions() *MetricOptions {
+	o.RLock()
+	defer o.RUnlock()
+	return &MetricOptions{
+		Path:            nuplRoute,
+		Asset:           o.Asset,
+		Interval:        o.Interval,
+		Since:           o.Since,
+		Until:           o.Until,
+		Format:          o.Format,
+		Currency:        o.Currency,
+		TimestampFormat: o.TimestampFormat,
 	}
 }
 
-func (n netUnrealizedProfitLossServicer) Get(options *NetUnrealizedProfitLossOptions) ([]*NetUnrealizedProfitLossEntry, error) {
-	resp, err := n.base.call(options)
-	if err != nil {
-		return nil, err
-	}
-	ns, err := parseNetUnrealizedProfitLoss(resp)
-	if err != nil {
-		return nil, err
-	}
-	return ns, nil
+type netUnrealizedProfitLossServicer struct {
+	metrics MetricService
 }
 
-func (n netUnrealizedProfitLossServicer) GetBatch(options *NetUnrealizedProfitLossOptions, ch chan<- ResultError) {
-	nupl, err := n.Get(options)
-	if err != nil {
-		ch <- ResultError{Error: err}
-	} else {
-		ch <- ResultError{Result: nupl}
+func newNetUnrealizedProfitLossService(metrics MetricService) NetUnrealizedProfitLossService {
+	return &netUnrealizedProfitLossServicer{
+		metrics: metrics,
 	}
 }
 
-func parseNetUnrealizedProfitLoss(resp *http.Response) ([]*NetUnrealizedProfitLossEntry, error) {
-	target := []*NetUnrealizedProfitLossEntry{}
-	err := json.NewDecoder(resp.Body).Decode(&target)
-	if err != nil {
-		return nil, err
-	}
-	return target, nil
+func (n netUnrealizedProfitLossServicer) Get(ctx context.Context, options *NetUnrealizedProfitLossOptions) ([]*NetUnrealizedProfitLossEntry, error) {
+	return n.metrics.Get(ctx, options.toMetricOptions())
 }
@@ -0,0 +1,96 @@
+package glassnode
+
+import (
+	"net/http"
+	"time"
+)
+
+// ClientOption - Configures the client built by NewGlassNodeClient
+type ClientOption func(*baseClient)
+
+// WithRateLimit - Limits the client to rate requests per second with bursts of up to burst requests, a rate of 0 disables limiting
+func WithRateLimit(rate float64, burst int) ClientOption {
+	return func(b *baseClient) {
+		b.limiter = newRateLimiter(rate, burst)
+	}
+}
+
+// WithMaxRetries - Sets how many times a request failing with a 429 or 5xx is retried, 0 disables retries
+func WithMaxRetries(n int) ClientOption {
+	return func(b *baseClient) {
+		if n < 0 {
+			n = 0
+		}
+		b.retry.maxRetries = n
+	}
+}
+
+// WithBackoff - Sets the initial and maximum delay of the exponential backoff between retries
+func WithBackoff(initial, max time.Duration) ClientOption {
+	return func(b *baseClient) {
+		b.retry.initialBackoff = initial
+		b.retry.maxBackoff = max
+	}
+}
+
+// WithRetryAfter - Sets whether the Retry-After header overrides the backoff delay
+func WithRetryAfter(respect bool) ClientOption {
+	return func(b *baseClient) {
+		b.retry.respectRetryAfter = respect
+	}
+}
+
+// WithBatchWorkers - Sets how many assets the batch methods fetch concurrently
+func WithBatchWorkers(n int) ClientOption {
+	return func(b *baseClient) {
+		if n < 1 {
+			n = 1
+		}
+		b.batchWorkers = n
+	}
+}
+
+// WithCatalogValidation - Checks the asset, interval, currency and format of every metric request against the endpoint catalogue before it is sent
+// The catalogue is fetched on the first request and cached, see CatalogService
+func WithCatalogValidation() ClientOption {
+	return func(b *baseClient) {
+		b.validateWithCatalog = true
+	}
+}
+
+// WithCache - Serves repeated requests from cache, responses expire based on the interval requested, see NewMemoryCache and NewFileCache
+func WithCache(cache Cache) ClientOption {
+	return func(b *baseClient) {
+		b.cache = cache
+	}
+}
+
+// WithHTTPClient - Sends every request through the given client instead of one with DefaultTimeout
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(b *baseClient) {
+		b.httpClient = client
+	}
+}
+
+// WithTransport - Sends every request through the given RoundTripper, the configured http.Client itself is left untouched
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(b *baseClient) {
+		c := *b.httpClient
+		c.Transport = transport
+		b.httpClient = &c
+	}
+}
+
+// WithBaseURL - Sends requests to baseURL instead of GlassNodeBaseURL, e.g. a local stand-in server
+func WithBaseURL(baseURL string) ClientOption {
+	return func(b *baseClient) {
+		b.baseURL = baseURL
+	}
+}
+
+// WithAPIKeyHeader - Sends the API key in the X-Api-Key header so it never appears in a URL
+func WithAPIKeyHeader() ClientOption {
+	return func(b *baseClient) {
+		b.headerAuth = true
+	}
+}
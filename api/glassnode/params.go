@@ -0,0 +1,72 @@
+package glassnode
+
+import "time"
+
+type Interval string
+
+const (
+	Interval10m     Interval = "10m"
+	Interval1h      Interval = "1h"
+	Interval24h     Interval = "24h"
+	Interval1w      Interval = "1w"
+	Interval1month  Interval = "1month"
+	IntervalDefault Interval = Interval24h
+)
+
+// Duration - Returns the length of a single interval, or 0 if the interval is not recognized
+// A month is treated as 30 days, which is only used to size chunks of long ranges
+func (i Interval) Duration() time.Duration {
+	switch i {
+	case Interval10m:
+		return 10 * time.Minute
+	case Interval1h:
+		return time.Hour
+	case Interval24h:
+		return 24 * time.Hour
+	case Interval1w:
+		return 7 * 24 * time.Hour
+	case Interval1month:
+		return 30 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Currency - Denomination of the returned values, only applies to metrics with a native and a USD variant
+type Currency string
+
+const (
+	CurrencyNative Currency = "NATIVE"
+	CurrencyUSD    Currency = "USD"
+)
+
+// TimestampFormat - How timestamps are formatted in the response, humanized timestamps are RFC3339
+type TimestampFormat string
+
+const (
+	TimestampFormatUnix      TimestampFormat = "unix"
+	TimestampFormatHumanized TimestampFormat = "humanized"
+)
+
+// validateParams - Checks every set parameter against the values known to the API, this does not need the catalogue
+func validateParams(path string, interval Interval, currency Currency, format Format, timestampFormat TimestampFormat) error {
+	if interval != "" && interval.Duration() == 0 {
+		return &UnsupportedOptionError{Path: path, Option: "interval", Value: string(interval)}
+	}
+	switch currency {
+	case "", CurrencyNative, CurrencyUSD:
+	default:
+		return &UnsupportedOptionError{Path: path, Option: "currency", Value: string(currency)}
+	}
+	switch format {
+	case "", FormatJSON, FormatCSV:
+	default:
+		return &UnsupportedOptionError{Path: path, Option: "format", Value: string(format)}
+	}
+	switch timestampFormat {
+	case "", TimestampFormatUnix, TimestampFormatHumanized:
+	default:
+		return &UnsupportedOptionError{Path: path, Option: "timestamp format", Value: string(timestampFormat)}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package glassnode
+
+import (
+	"encoding/json"
+)
+
+// OHLC - Values of a multi-value price point such as PriceOHLCRouteName, for use with Point.Decode
+type OHLC struct {
+	Open  float64 `json:"o"`
+	High  float64 `json:"h"`
+	Low   float64 `json:"l"`
+	Close float64 `json:"c"`
+}
+
+// Point - A metric point holding either a single value ({t, v}) or several named values ({t, o: {...}})
+// Value is nil for multi-value points and Object is nil for single value points
+type Point struct {
+	Timestamp int64
+	Value     *float64
+	Object    json.RawMessage
+}
+
+// UnmarshalJSON - Accepts both point shapes, with the timestamp as unix seconds or an RFC3339 string
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Timestamp json.RawMessage `json:"t"`
+		Value     *float64        `json:"v"`
+		Object    json.RawMessage `json:"o"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	ts, err := parseTimestamp(raw.Timestamp)
+	if err != nil {
+		return err
+	}
+	p.Timestamp = ts
+	p.Value = raw.Value
+	p.Object = nil
+	if len(raw.Object) > 0 && string(raw.Object) != "null" {
+		p.Object = raw.Object
+	}
+	return nil
+}
+
+// MarshalJSON - Encodes the point in the same shape it was returned by the API
+func (p *Point) MarshalJSON() ([]byte, error) {
+	if p.IsObject() {
+		return json.Marshal(struct {
+			Timestamp int64           `json:"t"`
+			Object    json.RawMessage `json:"o"`
+		}{p.Timestamp, p.Object})
+	}
+	return json.Marshal(struct {
+		Timestamp int64    `json:"t"`
+		Value     *float64 `json:"v"`
+	}{p.Timestamp, p.Value})
+}
+
+// IsObject - Returns true if the point holds several named values
+func (p *Point) IsObject() bool {
+	return p.Object != nil
+}
+
+// Map - Returns the named values of a multi-value point, values that are not numbers cause an error so use Decode for those
+func (p *Point) Map() (map[string]float64, error) {
+	if !p.IsObject() {
+		return nil, &SingleValueError{Timestamp: p.Timestamp}
+	}
+	m := map[string]float64{}
+	if err := json.Unmarshal(p.Object, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Decode - Decodes the named values of a multi-value point into v, typically a pointer to a struct with json tags
+func (p *Point) Decode(v interface{}) error {
+	if !p.IsObject() {
+		return &SingleValueError{Timestamp: p.Timestamp}
+	}
+	return json.Unmarshal(p.Object, v)
+}
+
+// Entry - Converts a single value point to a MetricEntry, a missing value is returned as 0
+func (p *Point) Entry() (*MetricEntry, error) {
+	if p.IsObject() {
+		return nil, &MultiValueError{Timestamp: p.Timestamp}
+	}
+	e := &MetricEntry{Timestamp: p.Timestamp}
+	if p.Value != nil {
+		e.Value = *p.Value
+	}
+	return e, nil
+}
+
+// pointsToEntries - Converts single value points to entries, failing on the first multi-value point
+func pointsToEntries(points []*Point) ([]*MetricEntry, error) {
+	entries := make([]*MetricEntry, len(points))
+	for i, p := range points {
+		e, err := p.Entry()
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = e
+	}
+	return entries, nil
+}
+
+// EntriesWithValues - Converts single value points to entries, leaving out the points whose value is null
+// Glassnode returns null for days it has no data, which Entry would otherwise turn into a 0
+func EntriesWithValues(points []*Point) (entries []*MetricEntry, skipped int, err error) {
+	entries = []*MetricEntry{}
+	for _, p := range points {
+		if !p.IsObject() && p.Value == nil {
+			skipped++
+			continue
+		}
+		e, err := p.Entry()
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, skipped, nil
+}
@@ -0,0 +1,69 @@
+package glassnode
+
+import (
+	"sort"
+	"time"
+)
+
+// MaxPointsPerRequest - Ranges spanning more than this many intervals are split into several requests
+const MaxPointsPerRequest = 2000
+
+type timeRange struct {
+	Since time.Time
+	Until time.Time
+}
+
+// validateRange - Returns an error if both ends of the range are set and since is not before until
+func validateRange(since, until time.Time) error {
+	if since.IsZero() || until.IsZero() {
+		return nil
+	}
+	if !since.Before(until) {
+		return &InvalidRangeError{Since: since, Until: until}
+	}
+	return nil
+}
+
+// splitRange - Splits [since, until] into consecutive chunks of at most MaxPointsPerRequest intervals
+// A range without a start is returned as is since its length is unknown, a missing end defaults to now
+func splitRange(since, until time.Time, interval Interval) []timeRange {
+	step := time.Duration(MaxPointsPerRequest) * interval.Duration()
+	if since.IsZero() || step <= 0 {
+		return []timeRange{{Since: since, Until: until}}
+	}
+	end := until
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if end.Sub(since) <= step {
+		return []timeRange{{Since: since, Until: until}}
+	}
+	chunks := []timeRange{}
+	for s := since; s.Before(end); s = s.Add(step) {
+		u := s.Add(step)
+		if u.After(end) {
+			u = end
+		}
+		chunks = append(chunks, timeRange{Since: s, Until: u})
+	}
+	return chunks
+}
+
+// mergePoints - Merges points from several requests, removing duplicate timestamps and sorting by timestamp
+func mergePoints(chunks [][]*Point) []*Point {
+	seen := map[int64]bool{}
+	merged := []*Point{}
+	for _, points := range chunks {
+		for _, p := range points {
+			if seen[p.Timestamp] {
+				continue
+			}
+			seen[p.Timestamp] = true
+			merged = append(merged, p)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Timestamp < merged[j].Timestamp
+	})
+	return merged
+}
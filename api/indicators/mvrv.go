@@ -0,0 +1,97 @@
+package indicators
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+)
+
+// ZScorePoint - MVRV Z-score along with the values it was computed from
+type ZScorePoint struct {
+	Timestamp   int64   `json:"t"`
+	MarketCap   float64 `json:"marketCap"`
+	RealizedCap float64 `json:"realizedCap"`
+	ZScore      float64 `json:"v"`
+}
+
+// MVRVZScore - Computes (market cap - realized cap) / stddev(market cap) for every timestamp present in both series
+// The standard deviation covers every market cap up to and including the point, so no future data is used
+// marketCap must start at the asset's first point, otherwise a day's score depends on where the series was cut
+// Points where the deviation is still 0 (e.g. the first one) are skipped, both series must be sorted by timestamp
+func MVRVZScore(marketCap []*glassnode.MetricEntry, realizedCap []*glassnode.MetricEntry) []*ZScorePoint {
+	realized := make(map[int64]float64, len(realizedCap))
+	for _, e := range realizedCap {
+		realized[e.Timestamp] = e.Value
+	}
+	points := []*ZScorePoint{}
+	// running mean and sum of squared differences (Welford)
+	var n, mean, m2 float64
+	for _, e := range marketCap {
+		n++
+		delta := e.Value - mean
+		mean += delta / n
+		m2 += delta * (e.Value - mean)
+		rc, ok := realized[e.Timestamp]
+		if !ok {
+			continue
+		}
+		stddev := math.Sqrt(m2 / n)
+		if stddev == 0 {
+			continue
+		}
+		points = append(points, &ZScorePoint{
+			Timestamp:   e.Timestamp,
+			MarketCap:   e.Value,
+			RealizedCap: rc,
+			ZScore:      (e.Value - rc) / stddev,
+		})
+	}
+	return points
+}
+
+// ZScoresInRange - Returns the points within [since, until], a zero since or until leaves that end open
+func ZScoresInRange(points []*ZScorePoint, since time.Time, until time.Time) []*ZScorePoint {
+	selected := []*ZScorePoint{}
+	for _, p := range points {
+		if !since.IsZero() && p.Timestamp < since.Unix() {
+			continue
+		}
+		if !until.IsZero() && p.Timestamp > until.Unix() {
+			continue
+		}
+		selected = append(selected, p)
+	}
+	return selected
+}
+
+// FetchMVRVZScore - Fetches the market cap and realized cap for the asset in options and computes the MVRV Z-score within its range
+// Market cap is always fetched from the asset's first point so the score of a day does not depend on the requested range
+// Days either series has no value for are skipped, the path of options is ignored
+func FetchMVRVZScore(ctx context.Context, c *glassnode.GlassNodeClient, options *glassnode.MetricOptions) ([]*ZScorePoint, error) {
+	series := map[glassnode.GlassNodeRouteName][]*glassnode.MetricEntry{}
+	for _, routeName := range []glassnode.GlassNodeRouteName{glassnode.MarketCapRouteName, glassnode.RealizedCapRouteName} {
+		path, ok := c.MetricPath(routeName)
+		if !ok {
+			return nil, &glassnode.RouteNotRecognizedError{Route: string(routeName)}
+		}
+		o := *options
+		o.Path = path
+		if routeName == glassnode.MarketCapRouteName {
+			o.Since = time.Time{}
+		}
+		points, err := c.Metrics.GetPoints(ctx, &o)
+		if err != nil {
+			return nil, err
+		}
+		// a null market cap would otherwise count as a 0 in the deviation of every later day
+		entries, _, err := glassnode.EntriesWithValues(points)
+		if err != nil {
+			return nil, err
+		}
+		series[routeName] = entries
+	}
+	points := MVRVZScore(series[glassnode.MarketCapRouteName], series[glassnode.RealizedCapRouteName])
+	return ZScoresInRange(points, options.Since, options.Until), nil
+}
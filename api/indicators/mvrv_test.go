@@ -0,0 +1,98 @@
+package indicators
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode/glassnodetest"
+)
+
+func dailyPoints(values ...float64) []*glassnode.Point {
+	points := make([]*glassnode.Point, len(values))
+	for i := range values {
+		points[i] = &glassnode.Point{Timestamp: day(i).Unix(), Value: &values[i]}
+	}
+	return points
+}
+
+func day(i int) time.Time {
+	return glassnodetest.Start.AddDate(0, 0, i)
+}
+
+func TestFetchMVRVZScoreIndependentOfRange(t *testing.T) {
+	// Market cap 10, 20, 30, 40 and realized cap 5, 5, 10, 10
+	// day 1: mean 15, stddev 5, z = (20 - 5) / 5 = 3
+	// day 2: mean 20, stddev sqrt(200/3), z = (30 - 10) / sqrt(200/3) = sqrt(6)
+	// day 3: mean 25, stddev sqrt(125), z = (40 - 10) / sqrt(125) = 6 / sqrt(5)
+	s := glassnodetest.NewServer(&glassnodetest.Fixtures{
+		Series: map[string]map[string][]*glassnode.Point{
+			glassnodetest.MarketCapPath:   {glassnode.BTC: dailyPoints(10, 20, 30, 40)},
+			glassnodetest.RealizedCapPath: {glassnode.BTC: dailyPoints(5, 5, 10, 10)},
+		},
+	})
+	defer s.Close()
+	c := s.Client()
+
+	tests := []struct {
+		since, until int
+		want         []float64
+	}{
+		{1, 2, []float64{3, math.Sqrt(6)}},
+		{2, 3, []float64{math.Sqrt(6), 6 / math.Sqrt(5)}},
+	}
+	for _, tt := range tests {
+		options := glassnode.DefaultMetricOptions("")
+		options.Asset = glassnode.BTC
+		options.Since = day(tt.since)
+		options.Until = day(tt.until)
+		points, err := FetchMVRVZScore(context.Background(), c, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(points) != len(tt.want) {
+			t.Fatalf("days %d-%d: got %d points, want %d", tt.since, tt.until, len(points), len(tt.want))
+		}
+		for i, p := range points {
+			if p.Timestamp != day(tt.since+i).Unix() || math.Abs(p.ZScore-tt.want[i]) > 1e-9 {
+				t.Fatalf("days %d-%d: point %d = {%d %v}, want {%d %v}", tt.since, tt.until, i, p.Timestamp, p.ZScore, day(tt.since+i).Unix(), tt.want[i])
+			}
+		}
+	}
+}
+
+func TestFetchMVRVZScoreSkipsNullPoints(t *testing.T) {
+	// Same series as above with a null market cap on day 2 and a null realized cap on day 4
+	// day 2 is left out of the deviation, so day 3 scores like day 2 did above
+	marketCap := dailyPoints(10, 20, 0, 30, 40)
+	marketCap[2].Value = nil
+	realizedCap := dailyPoints(5, 5, 7, 10, 0)
+	realizedCap[4].Value = nil
+	s := glassnodetest.NewServer(&glassnodetest.Fixtures{
+		Series: map[string]map[string][]*glassnode.Point{
+			glassnodetest.MarketCapPath:   {glassnode.BTC: marketCap},
+			glassnodetest.RealizedCapPath: {glassnode.BTC: realizedCap},
+		},
+	})
+	defer s.Close()
+
+	options := glassnode.DefaultMetricOptions("")
+	options.Asset = glassnode.BTC
+	options.Since = day(1)
+	options.Until = day(4)
+	points, err := FetchMVRVZScore(context.Background(), s.Client(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int64]float64{day(1).Unix(): 3, day(3).Unix(): math.Sqrt(6)}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d", len(points), len(want))
+	}
+	for _, p := range points {
+		if z, ok := want[p.Timestamp]; !ok || math.Abs(p.ZScore-z) > 1e-9 {
+			t.Fatalf("got {%d %v}, want one of %v", p.Timestamp, p.ZScore, want)
+		}
+	}
+}
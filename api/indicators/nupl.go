@@ -0,0 +1,90 @@
+// Package indicators derives higher level on-chain indicators from glassnode metric series
+package indicators
+
+import (
+	"context"
+
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+)
+
+// Regime - Market sentiment implied by the net unrealized profit/loss
+type Regime string
+
+const (
+	Capitulation    Regime = "capitulation"
+	HopeFear        Regime = "hope/fear"
+	OptimismAnxiety Regime = "optimism/anxiety"
+	BeliefDenial    Regime = "belief/denial"
+	EuphoriaGreed   Regime = "euphoria/greed"
+)
+
+// Lower bounds of each regime, a NUPL below HopeFearThreshold is Capitulation
+const (
+	HopeFearThreshold        = 0.0
+	OptimismAnxietyThreshold = 0.25
+	BeliefDenialThreshold    = 0.5
+	EuphoriaGreedThreshold   = 0.75
+)
+
+// NuplRegime - Returns the regime for a single NUPL value
+func NuplRegime(value float64) Regime {
+	switch {
+	case value < HopeFearThreshold:
+		return Capitulation
+	case value < OptimismAnxietyThreshold:
+		return HopeFear
+	case value < BeliefDenialThreshold:
+		return OptimismAnxiety
+	case value < EuphoriaGreedThreshold:
+		return BeliefDenial
+	default:
+		return EuphoriaGreed
+	}
+}
+
+// RegimePoint - A NUPL point labelled with its regime
+type RegimePoint struct {
+	Timestamp int64   `json:"t"`
+	Value     float64 `json:"v"`
+	Regime    Regime  `json:"regime"`
+}
+
+// RegimeChange - The first point of a new regime
+type RegimeChange struct {
+	Timestamp int64  `json:"t"`
+	From      Regime `json:"from"`
+	To        Regime `json:"to"`
+}
+
+// LabelNupl - Labels every entry with its regime, keeping the order of entries
+func LabelNupl(entries []*glassnode.NetUnrealizedProfitLossEntry) []*RegimePoint {
+	points := make([]*RegimePoint, len(entries))
+	for i, e := range entries {
+		points[i] = &RegimePoint{Timestamp: e.Timestamp, Value: e.Value, Regime: NuplRegime(e.Value)}
+	}
+	return points
+}
+
+// RegimeChanges - Returns every point whose regime differs from the previous point, points must be sorted by timestamp
+func RegimeChanges(points []*RegimePoint) []*RegimeChange {
+	changes := []*RegimeChange{}
+	for i := 1; i < len(points); i++ {
+		if points[i].Regime != points[i-1].Regime {
+			changes = append(changes, &RegimeChange{
+				Timestamp: points[i].Timestamp,
+				From:      points[i-1].Regime,
+				To:        points[i].Regime,
+			})
+		}
+	}
+	return changes
+}
+
+// NuplRegimes - Fetches the NUPL for the asset and range in options and labels every point
+func NuplRegimes(ctx context.Context, c *glassnode.GlassNodeClient, options *glassnode.NetUnrealizedProfitLossOptions) ([]*RegimePoint, error) {
+	entries, err := c.NetUnrealizedProfitLoss.Get(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	return LabelNupl(entries), nil
+}
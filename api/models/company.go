@@ -0,0 +1,94 @@
+package models
+
+import (
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+
+	iex "github.com/goinvest/iexcloud/v2"
+)
+
+// Company - An item of the Company table, the company summary of a symbol as returned by IEX
+// SectorIndex lists the companies of a sector by symbol along with their names
+type Company struct {
+	Symbol       string `at:"S" kt:"HASH" gsi:"SectorIndex,RANGE"`
+	CompanyName  string `gsi:"SectorIndex,INCLUDE"`
+	Exchange     string
+	Industry     string
+	Website      string
+	Description  string
+	CEO          string
+	SecurityName string
+	IssueType    string
+	// DynamoDB rejects empty index keys, so Sector is left out when unknown
+	Sector         string `at:"S" gsi:"SectorIndex,HASH" dynamodbav:",omitempty"`
+	PrimarySicCode int
+	Employees      int
+	// DynamoDB rejects empty sets, so Tags is left out when there are none
+	Tags     []string `dynamodbav:",stringset,omitempty"`
+	Address  string
+	Address2 string
+	State    string
+	City     string
+	Zip      string
+	Country  string
+	Phone    string
+}
+
+func (Company) TableOptions() dynamodbutil.TableOptions {
+	return dynamodbutil.TableOptions{
+		TableName:          CompanyTableName,
+		ReadCapacityUnits:  1,
+		WriteCapacityUnits: 1,
+	}
+}
+
+// NewCompany - Converts the IEX company summary of the symbol to a Company item
+func NewCompany(symbol string, c iex.Company) Company {
+	return Company{
+		Symbol:         symbol,
+		CompanyName:    c.Name,
+		Exchange:       c.Exchange,
+		Industry:       c.Industry,
+		Website:        c.Website,
+		Description:    c.Description,
+		CEO:            c.CEO,
+		SecurityName:   c.SecurityName,
+		IssueType:      c.IssueType,
+		Sector:         c.Sector,
+		PrimarySicCode: c.PrimarySICCode,
+		Employees:      c.Employees,
+		Tags:           c.Tags,
+		Address:        c.Address,
+		Address2:       c.Address2,
+		State:          c.State,
+		City:           c.City,
+		Zip:            c.Zip,
+		Country:        c.Country,
+		Phone:          c.Phone,
+	}
+}
+
+// IEX - Converts the item back to the IEX company summary
+func (c Company) IEX() iex.Company {
+	return iex.Company{
+		Symbol:         c.Symbol,
+		Name:           c.CompanyName,
+		Exchange:       c.Exchange,
+		Industry:       c.Industry,
+		Website:        c.Website,
+		Description:    c.Description,
+		CEO:            c.CEO,
+		IssueType:      c.IssueType,
+		Sector:         c.Sector,
+		Employees:      c.Employees,
+		Tags:           c.Tags,
+		SecurityName:   c.SecurityName,
+		PrimarySICCode: c.PrimarySicCode,
+		Address:        c.Address,
+		Address2:       c.Address2,
+		State:          c.State,
+		City:           c.City,
+		Zip:            c.Zip,
+		Country:        c.Country,
+		Phone:          c.Phone,
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+
+	iex "github.com/goinvest/iexcloud/v2"
+)
+
+// Historical - An item of the Historical table, one per symbol and day
+type Historical struct {
+	Symbol        string `at:"S" kt:"HASH"`
+	Date          string `at:"S" kt:"RANGE"`
+	Open          float64
+	High          float64
+	Low           float64
+	Close         float64
+	Volume        int
+	Change        float64
+	ChangePercent float64
+}
+
+func (Historical) TableOptions() dynamodbutil.TableOptions {
+	return dynamodbutil.TableOptions{
+		TableName:          HistoricalTableName,
+		ReadCapacityUnits:  1,
+		WriteCapacityUnits: 1,
+	}
+}
+
+// NewHistorical - Converts daily data points of the symbol to Historical items
+// Change is computed against the previous close, or against the open for the first point
+func NewHistorical(symbol string, historical []iex.HistoricalDataPoint) []Historical {
+	items := make([]Historical, len(historical))
+	var change, changePercent float64
+	for i, data := range historical {
+		if i == 0 {
+			change = data.Close - data.Open
+			changePercent = change / data.Open
+		} else {
+			change = data.Close - historical[i-1].Close
+			changePercent = change / historical[i-1].Close
+		}
+		items[i] = Historical{
+			Symbol:        symbol,
+			Date:          data.Date,
+			Open:          data.Open,
+			High:          data.High,
+			Low:           data.Low,
+			Close:         data.Close,
+			Volume:        data.Volume,
+			Change:        change,
+			ChangePercent: changePercent,
+		}
+	}
+	return items
+}
+
+// IEX - Converts the item back to an IEX data point
+func (h Historical) IEX() iex.HistoricalDataPoint {
+	return iex.HistoricalDataPoint{
+		Date:          h.Date,
+		Open:          h.Open,
+		High:          h.High,
+		Low:           h.Low,
+		Close:         h.Close,
+		Volume:        h.Volume,
+		Change:        h.Change,
+		ChangePercent: h.ChangePercent,
+	}
+}
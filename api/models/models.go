@@ -0,0 +1,39 @@
+// Package models holds the items stored in the DynamoDB tables, their tags and TableOptions describe the tables declared in serverless/services/dynamodb/serverless.yml
+package models
+
+import (
+	"time"
+
+	iex "github.com/goinvest/iexcloud/v2"
+)
+
+const (
+	SymbolsTableName    = "Symbols"
+	CompanyTableName    = "Company"
+	HistoricalTableName = "Historical"
+	StatsTableName      = "Stats"
+	OnChainTableName    = "OnChain"
+)
+
+const (
+	// SymbolAttribute - HASH key of the Symbols, Company, Historical and Stats tables
+	SymbolAttribute = "Symbol"
+	// DateAttribute - RANGE key of the Historical table
+	DateAttribute = "Date"
+)
+
+// DateLayout - Dates are stored as strings in this layout
+const DateLayout = "2006-01-02"
+
+func formatDate(d iex.Date) string {
+	return time.Time(d).Format(DateLayout)
+}
+
+// parseDate - Dates that cannot be parsed are returned as the zero date
+func parseDate(s string) iex.Date {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return iex.Date{}
+	}
+	return iex.Date(t)
+}
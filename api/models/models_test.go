@@ -0,0 +1,56 @@
+package models_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+)
+
+const serverlessTemplate = "../../serverless/services/dynamodb/serverless.yml"
+
+// declaredResources - Returns the `resources.Resources` section of the template, unindented to the top level
+func declaredResources(t *testing.T) string {
+	t.Helper()
+	b, err := ioutil.ReadFile(serverlessTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := []string{}
+	inResources := false
+	for _, line := range strings.Split(string(b), "\n") {
+		switch {
+		case line == "  Resources:":
+			inResources = true
+		case inResources && !strings.HasPrefix(line, "    "):
+			inResources = false
+		}
+		if inResources {
+			lines = append(lines, strings.TrimPrefix(line, "  "))
+		}
+	}
+	if len(lines) == 0 {
+		t.Fatalf("%s declares no Resources", serverlessTemplate)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// TestDefinitionsMatchServerless - The registered models must describe the tables deployed by serverless/services/dynamodb
+// Run `go run ./cmd/tablegen cfn` and paste its output into the template when this fails after changing a model
+func TestDefinitionsMatchServerless(t *testing.T) {
+	defs, err := models.Definitions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := dynamodbutil.WriteCloudFormation(&buf, defs...); err != nil {
+		t.Fatal(err)
+	}
+	want := declaredResources(t)
+	if got := buf.String(); got != want {
+		t.Fatalf("generated resources differ from %s\ngot\n%s\nwant\n%s", serverlessTemplate, got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+)
+
+// OnChainEntry - A single metric point as stored in the OnChain table, keyed by series (asset+metric) and timestamp
+type OnChainEntry struct {
+	Series    string `at:"S" kt:"HASH"`
+	Timestamp int64  `at:"N" kt:"RANGE"`
+	Asset     string
+	Metric    string
+	Value     float64
+}
+
+// TableOptions - Matches the OnChain table declared in serverless/services/dynamodb/serverless.yml
+func (OnChainEntry) TableOptions() dynamodbutil.TableOptions {
+	return dynamodbutil.TableOptions{
+		TableName:          OnChainTableName,
+		ReadCapacityUnits:  1,
+		WriteCapacityUnits: 1,
+	}
+}
+
+// SeriesKey - Returns the partition key for the asset's metric series, e.g. BTC#nupl
+func SeriesKey(asset string, metric glassnode.GlassNodeRouteName) string {
+	return fmt.Sprintf("%s#%s", asset, metric)
+}
+
+// NewOnChainEntries - Converts the entries returned by the API for an asset's metric to entries ready to be stored
+func NewOnChainEntries(asset string, metric glassnode.GlassNodeRouteName, entries []*glassnode.MetricEntry) []*OnChainEntry {
+	series := SeriesKey(asset, metric)
+	out := make([]*OnChainEntry, len(entries))
+	for i, e := range entries {
+		out[i] = &OnChainEntry{
+			Series:    series,
+			Timestamp: e.Timestamp,
+			Asset:     asset,
+			Metric:    string(metric),
+			Value:     e.Value,
+		}
+	}
+	return out
+}
+
+// MetricEntry - Converts the stored entry back to the shape returned by the API
+func (e *OnChainEntry) MetricEntry() *glassnode.MetricEntry {
+	return &glassnode.MetricEntry{Timestamp: e.Timestamp, Value: e.Value}
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+)
+
+var (
+	registry   = []interface{}{}
+	registryMu sync.RWMutex
+)
+
+func init() {
+	Register(Symbol{})
+	Register(Company{})
+	Register(Historical{})
+	Register(Stats{})
+	Register(OnChainEntry{})
+}
+
+// Register - Adds a model to the tables generated by cmd/tablegen, registering two models for the same table panics
+func Register(model interface{}) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	name := dynamodbutil.TableNameFromStruct(model)
+	for _, m := range registry {
+		if dynamodbutil.TableNameFromStruct(m) == name {
+			panic(fmt.Sprintf("models: table %s registered twice", name))
+		}
+	}
+	registry = append(registry, model)
+}
+
+// Registered - Returns the registered models in the order they were registered
+func Registered() []interface{} {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	models := make([]interface{}, len(registry))
+	copy(models, registry)
+	return models
+}
+
+// Definitions - Returns the table definitions of every registered model
+func Definitions() ([]*dynamodbutil.TableDefinition, error) {
+	defs := []*dynamodbutil.TableDefinition{}
+	for _, m := range Registered() {
+		def, err := dynamodbutil.TableDefinitionFromStruct(m)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
@@ -0,0 +1,186 @@
+package models
+
+import (
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+
+	iex "github.com/goinvest/iexcloud/v2"
+)
+
+// Stats - An item of the Stats table, the advanced stats of a symbol as returned by IEX with dates stored as DateLayout strings
+type Stats struct {
+	Symbol                   string `at:"S" kt:"HASH"`
+	MarketCap                float64
+	Week52High               float64
+	Week52Low                float64
+	Week52Change             float64
+	SharesOutstanding        float64
+	Float                    float64
+	Avg10Volume              float64
+	Avg30Volume              float64
+	Day200MovingAvg          float64
+	Day50MovingAvg           float64
+	Employees                int
+	TTMEPS                   float64
+	TTMDividendRate          float64
+	DividendYield            float64
+	NextDividendDate         string
+	ExDividendDate           string
+	NextEarningsDate         string
+	PERatio                  float64
+	Beta                     float64
+	MaxChangePercent         float64
+	Year5ChangePercent       float64
+	Year2ChangePercent       float64
+	Year1ChangePercent       float64
+	YTDChangePercent         float64
+	Month6ChangePercent      float64
+	Month3ChangePercent      float64
+	Month1ChangePercent      float64
+	Day30ChangePercent       float64
+	Day5ChangePercent        float64
+	TotalCash                float64
+	CurrentDebt              float64
+	Revenue                  float64
+	GrossProfit              float64
+	TotalRevenue             float64
+	EBITDA                   float64
+	RevenuePerShare          float64
+	RevenuePerEmployee       float64
+	DebtToEquity             float64
+	ProfitMargin             float64
+	EnterpriseValue          float64
+	EnterpriseValueToRevenue float64
+	PriceToSales             float64
+	PriceToBook              float64
+	ForwardPERatio           float64
+	PEGRatio                 float64
+	PEHigh                   float64
+	PELow                    float64
+	Week52HighDate           string
+	Week52LowDate            string
+	PutCallRatio             float64
+}
+
+func (Stats) TableOptions() dynamodbutil.TableOptions {
+	return dynamodbutil.TableOptions{
+		TableName:          StatsTableName,
+		ReadCapacityUnits:  1,
+		WriteCapacityUnits: 1,
+	}
+}
+
+// NewStats - Converts the IEX advanced stats of the symbol to a Stats item
+func NewStats(symbol string, s iex.AdvancedStats) Stats {
+	return Stats{
+		Symbol:                   symbol,
+		MarketCap:                s.MarketCap,
+		Week52High:               s.Week52High,
+		Week52Low:                s.Week52Low,
+		Week52Change:             s.Week52Change,
+		SharesOutstanding:        s.SharesOutstanding,
+		Float:                    s.Float,
+		Avg10Volume:              s.Avg10Volume,
+		Avg30Volume:              s.Avg30Volume,
+		Day200MovingAvg:          s.Day200MovingAvg,
+		Day50MovingAvg:           s.Day50MovingAvg,
+		Employees:                s.Employees,
+		TTMEPS:                   s.TTMEPS,
+		TTMDividendRate:          s.TTMDividendRate,
+		DividendYield:            s.DividendYield,
+		NextDividendDate:         formatDate(s.NextDividendDate),
+		ExDividendDate:           formatDate(s.ExDividendDate),
+		NextEarningsDate:         formatDate(s.NextEarningsDate),
+		PERatio:                  s.PERatio,
+		Beta:                     s.Beta,
+		MaxChangePercent:         s.MaxChangePercent,
+		Year5ChangePercent:       s.Year5ChangePercent,
+		Year2ChangePercent:       s.Year2ChangePercent,
+		Year1ChangePercent:       s.Year1ChangePercent,
+		YTDChangePercent:         s.YTDChangePercent,
+		Month6ChangePercent:      s.Month6ChangePercent,
+		Month3ChangePercent:      s.Month3ChangePercent,
+		Month1ChangePercent:      s.Month1ChangePercent,
+		Day30ChangePercent:       s.Day30ChangePercent,
+		Day5ChangePercent:        s.Day5ChangePercent,
+		TotalCash:                s.TotalCash,
+		CurrentDebt:              s.CurrentDebt,
+		Revenue:                  s.Revenue,
+		GrossProfit:              s.GrossProfit,
+		TotalRevenue:             s.TotalRevenue,
+		EBITDA:                   s.EBITDA,
+		RevenuePerShare:          s.RevenuePerShare,
+		RevenuePerEmployee:       s.RevenuePerEmployee,
+		DebtToEquity:             s.DebtToEquity,
+		ProfitMargin:             s.ProfitMargin,
+		EnterpriseValue:          s.EnterpriseValue,
+		EnterpriseValueToRevenue: s.EnterpriseValueToRevenue,
+		PriceToSales:             s.PriceToSales,
+		PriceToBook:              s.PriceToBook,
+		ForwardPERatio:           s.ForwardPERatio,
+		PEGRatio:                 s.PEGRatio,
+		PEHigh:                   s.PEHigh,
+		PELow:                    s.PELow,
+		Week52HighDate:           formatDate(s.Week52HighDate),
+		Week52LowDate:            formatDate(s.Week52LowDate),
+		PutCallRatio:             s.PutCallRatio,
+	}
+}
+
+// IEX - Converts the item back to the IEX advanced stats, dates that cannot be parsed are left at the zero date
+func (s Stats) IEX() iex.AdvancedStats {
+	return iex.AdvancedStats{
+		KeyStats: iex.KeyStats{
+			MarketCap:           s.MarketCap,
+			Week52High:          s.Week52High,
+			Week52Low:           s.Week52Low,
+			Week52Change:        s.Week52Change,
+			SharesOutstanding:   s.SharesOutstanding,
+			Float:               s.Float,
+			Avg10Volume:         s.Avg10Volume,
+			Avg30Volume:         s.Avg30Volume,
+			Day200MovingAvg:     s.Day200MovingAvg,
+			Day50MovingAvg:      s.Day50MovingAvg,
+			Employees:           s.Employees,
+			TTMEPS:              s.TTMEPS,
+			TTMDividendRate:     s.TTMDividendRate,
+			DividendYield:       s.DividendYield,
+			NextDividendDate:    parseDate(s.NextDividendDate),
+			ExDividendDate:      parseDate(s.ExDividendDate),
+			NextEarningsDate:    parseDate(s.NextEarningsDate),
+			PERatio:             s.PERatio,
+			Beta:                s.Beta,
+			MaxChangePercent:    s.MaxChangePercent,
+			Year5ChangePercent:  s.Year5ChangePercent,
+			Year2ChangePercent:  s.Year2ChangePercent,
+			Year1ChangePercent:  s.Year1ChangePercent,
+			YTDChangePercent:    s.YTDChangePercent,
+			Month6ChangePercent: s.Month6ChangePercent,
+			Month3ChangePercent: s.Month3ChangePercent,
+			Month1ChangePercent: s.Month1ChangePercent,
+			Day30ChangePercent:  s.Day30ChangePercent,
+			Day5ChangePercent:   s.Day5ChangePercent,
+		},
+		Beta:                     s.Beta,
+		TotalCash:                s.TotalCash,
+		CurrentDebt:              s.CurrentDebt,
+		Revenue:                  s.Revenue,
+		GrossProfit:              s.GrossProfit,
+		TotalRevenue:             s.TotalRevenue,
+		EBITDA:                   s.EBITDA,
+		RevenuePerShare:          s.RevenuePerShare,
+		RevenuePerEmployee:       s.RevenuePerEmployee,
+		DebtToEquity:             s.DebtToEquity,
+		ProfitMargin:             s.ProfitMargin,
+		EnterpriseValue:          s.EnterpriseValue,
+		EnterpriseValueToRevenue: s.EnterpriseValueToRevenue,
+		PriceToSales:             s.PriceToSales,
+		PriceToBook:              s.PriceToBook,
+		ForwardPERatio:           s.ForwardPERatio,
+		PEGRatio:                 s.PEGRatio,
+		PEHigh:                   s.PEHigh,
+		PELow:                    s.PELow,
+		Week52HighDate:           parseDate(s.Week52HighDate),
+		Week52LowDate:            parseDate(s.Week52LowDate),
+		PutCallRatio:             s.PutCallRatio,
+	}
+}
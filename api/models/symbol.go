@@ -0,0 +1,21 @@
+package models
+
+import (
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+)
+
+// Symbol - An item of the Symbols table, inserting one triggers the symbol subscribers through the table's stream
+type Symbol struct {
+	Symbol string `at:"S" kt:"HASH"`
+}
+
+// TableOptions - Symbols streams new images to the subscribers
+func (Symbol) TableOptions() dynamodbutil.TableOptions {
+	return dynamodbutil.TableOptions{
+		TableName:          SymbolsTableName,
+		StreamViewType:     dynamodb.StreamViewTypeNewImage,
+		ReadCapacityUnits:  1,
+		WriteCapacityUnits: 1,
+	}
+}
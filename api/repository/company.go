@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+)
+
+// CompanyRepository - Company summaries by symbol
+type CompanyRepository interface {
+	Get(ctx context.Context, symbol string) (*models.Company, error)
+	GetMany(ctx context.Context, symbols []string) ([]models.Company, error)
+	Put(ctx context.Context, company models.Company) error
+}
+
+type companyRepository struct {
+	client dynamodbiface.DynamoDBAPI
+}
+
+func NewCompanyRepository(client dynamodbiface.DynamoDBAPI) CompanyRepository {
+	return &companyRepository{client: client}
+}
+
+// Get - Returns an ErrorNotFound if there is no company summary for the symbol
+func (r *companyRepository) Get(ctx context.Context, symbol string) (*models.Company, error) {
+	company := &models.Company{}
+	if err := getBySymbol(ctx, r.client, models.CompanyTableName, symbol, company); err != nil {
+		return nil, err
+	}
+	return company, nil
+}
+
+// GetMany - Returns the company summaries in the order of the symbols, symbols without one are skipped
+func (r *companyRepository) GetMany(ctx context.Context, symbols []string) ([]models.Company, error) {
+	companies := []models.Company{}
+	if err := getManyBySymbol(ctx, r.client, models.CompanyTableName, symbols, &companies); err != nil {
+		return nil, err
+	}
+	return companies, nil
+}
+
+func (r *companyRepository) Put(ctx context.Context, company models.Company) error {
+	_, err := put(ctx, r.client, []models.Company{company})
+	return err
+}
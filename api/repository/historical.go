@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+)
+
+// HistoricalRepository - Daily prices by symbol
+type HistoricalRepository interface {
+	ForSymbol(ctx context.Context, symbol string) ([]models.Historical, error)
+	Put(ctx context.Context, historical []models.Historical) (*dynamodbutil.BatchWriteResult, error)
+}
+
+type historicalRepository struct {
+	client dynamodbiface.DynamoDBAPI
+}
+
+func NewHistoricalRepository(client dynamodbiface.DynamoDBAPI) HistoricalRepository {
+	return &historicalRepository{client: client}
+}
+
+// ForSymbol - Returns every day stored for the symbol, oldest first, or an ErrorNotFound if there are none
+func (r *historicalRepository) ForSymbol(ctx context.Context, symbol string) ([]models.Historical, error) {
+	historical := []models.Historical{}
+	var unmarshalErr error
+	err := r.client.QueryPagesWithContext(ctx, &db.QueryInput{
+		TableName:              aws.String(models.HistoricalTableName),
+		KeyConditionExpression: aws.String("#pk = :s"),
+		ExpressionAttributeNames: map[string]*string{
+			"#pk": aws.String(models.SymbolAttribute),
+		},
+		ExpressionAttributeValues: map[string]*db.AttributeValue{
+			":s": {S: aws.String(symbol)},
+		},
+	}, func(page *db.QueryOutput, _ bool) bool {
+		h := []models.Historical{}
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &h); unmarshalErr != nil {
+			return false
+		}
+		historical = append(historical, h...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	if len(historical) == 0 {
+		return nil, NewErrorNotFound(models.HistoricalTableName, symbol)
+	}
+	return historical, nil
+}
+
+// Put - Writes the days in batches, the result holds the written and failed counts
+func (r *historicalRepository) Put(ctx context.Context, historical []models.Historical) (*dynamodbutil.BatchWriteResult, error) {
+	return put(ctx, r.client, historical)
+}
@@ -0,0 +1,78 @@
+// Package repository reads and writes the Symbols, Company, Stats and Historical tables
+// The tables' names, keys and attributes are defined by the items in api/models, callers never build attribute maps themselves
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+)
+
+// ErrorNotFound - Returned when a table holds no item for the symbol
+type ErrorNotFound struct {
+	Table  string
+	Symbol string
+}
+
+func (e *ErrorNotFound) Error() string {
+	return fmt.Sprintf("Error: no %s item found for %s", e.Table, e.Symbol)
+}
+
+func NewErrorNotFound(table string, symbol string) *ErrorNotFound {
+	return &ErrorNotFound{Table: table, Symbol: symbol}
+}
+
+// IsNotFound - Reports whether the error is an ErrorNotFound
+func IsNotFound(err error) bool {
+	_, ok := err.(*ErrorNotFound)
+	return ok
+}
+
+func symbolKey(symbol string) map[string]*db.AttributeValue {
+	return map[string]*db.AttributeValue{
+		models.SymbolAttribute: {S: aws.String(symbol)},
+	}
+}
+
+// getBySymbol - Reads the item of the symbol from the table into out
+func getBySymbol(ctx context.Context, client dynamodbiface.DynamoDBAPI, table string, symbol string, out interface{}) error {
+	res, err := client.GetItemWithContext(ctx, &db.GetItemInput{
+		TableName: aws.String(table),
+		Key:       symbolKey(symbol),
+	})
+	if err != nil {
+		return err
+	}
+	if len(res.Item) == 0 {
+		return NewErrorNotFound(table, symbol)
+	}
+	return dynamodbattribute.UnmarshalMap(res.Item, out)
+}
+
+// getManyBySymbol - Reads the items of the symbols from the table into out, a pointer to a slice, symbols without an item are skipped
+func getManyBySymbol(ctx context.Context, client dynamodbiface.DynamoDBAPI, table string, symbols []string, out interface{}) error {
+	getter := dynamodbutil.NewBatchGetter(client)
+	for _, symbol := range symbols {
+		getter.Get(table, symbolKey(symbol))
+	}
+	res, err := getter.Execute(ctx)
+	if err != nil {
+		return err
+	}
+	return res.Unmarshal(table, out)
+}
+
+// put - Writes a slice of items to the table named by their TableOptions
+func put(ctx context.Context, client dynamodbiface.DynamoDBAPI, items interface{}) (*dynamodbutil.BatchWriteResult, error) {
+	writer := dynamodbutil.NewBatchWriter(client)
+	if err := writer.PutItems(items); err != nil {
+		return nil, err
+	}
+	return writer.Flush(ctx)
+}
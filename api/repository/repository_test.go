@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	iex "github.com/goinvest/iexcloud/v2"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil/dynamodbtest"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+)
+
+// newTestDB - Returns an in-memory DB with every registered table
+func newTestDB(t *testing.T) *dynamodbtest.DB {
+	t.Helper()
+	d, err := dynamodbtest.NewFromModels(models.Registered()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func symbols(n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = fmt.Sprintf("S%03d", i)
+	}
+	return out
+}
+
+func TestSymbolRepository(t *testing.T) {
+	ctx := context.Background()
+	r := NewSymbolRepository(newTestDB(t))
+	want := symbols(130)
+	if err := r.Put(ctx, want...); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %d symbols, want %d", len(got), len(want))
+	}
+}
+
+func TestCompanyRepository(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	r := NewCompanyRepository(d)
+	all := symbols(130)
+	for _, symbol := range all {
+		if err := r.Put(ctx, models.NewCompany(symbol, iex.Company{Name: "Company " + symbol, Tags: []string{"Tech"}})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	company, err := r.Get(ctx, "S042")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if company.CompanyName != "Company S042" || len(company.Tags) != 1 {
+		t.Fatalf("got %+v, want the stored company", company)
+	}
+	if _, err := r.Get(ctx, "MISSING"); !IsNotFound(err) {
+		t.Fatalf("got error %v, want ErrorNotFound", err)
+	}
+
+	// more keys than a single BatchGetItem with some left unprocessed twice
+	d.Inject(dynamodbtest.OpBatchGetItem, dynamodbtest.Fault{Unprocessed: 30, Times: 2})
+	companies, err := r.GetMany(ctx, append([]string{"MISSING"}, all...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(companies) != len(all) {
+		t.Fatalf("got %d companies, want %d", len(companies), len(all))
+	}
+	for i, c := range companies {
+		if c.Symbol != all[i] {
+			t.Fatalf("company %d is %s, want %s", i, c.Symbol, all[i])
+		}
+	}
+
+	failure := errors.New("dynamodb unavailable")
+	d.Inject(dynamodbtest.OpGetItem, dynamodbtest.ErrorFault(failure))
+	if _, err := r.Get(ctx, "S001"); err != failure {
+		t.Fatalf("got error %v, want %v", err, failure)
+	}
+}
+
+func TestStatsRepository(t *testing.T) {
+	ctx := context.Background()
+	r := NewStatsRepository(newTestDB(t))
+	if err := r.Put(ctx, models.NewStats("AAPL", iex.AdvancedStats{})); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := r.Get(ctx, "AAPL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Symbol != "AAPL" {
+		t.Fatalf("got %+v, want the AAPL stats", stats)
+	}
+	if _, err := r.Get(ctx, "MSFT"); !IsNotFound(err) {
+		t.Fatalf("got error %v, want ErrorNotFound", err)
+	}
+}
+
+func historicalPoints(n int) []iex.HistoricalDataPoint {
+	points := make([]iex.HistoricalDataPoint, n)
+	for i := range points {
+		points[i] = iex.HistoricalDataPoint{
+			Date:  fmt.Sprintf("2020-%02d-%02d", i/28+1, i%28+1),
+			Open:  float64(100 + i),
+			Close: float64(101 + i),
+		}
+	}
+	return points
+}
+
+func TestHistoricalRepository(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	r := NewHistoricalRepository(d)
+	points := historicalPoints(60)
+
+	// a throttled batch and a partially processed one are retried by the BatchWriter
+	throttle := dynamodbtest.ThrottleFault()
+	throttle.Times = 1
+	d.Inject(dynamodbtest.OpBatchWriteItem, throttle)
+	d.Inject(dynamodbtest.OpBatchWriteItem, dynamodbtest.Fault{Unprocessed: 5, Times: 1})
+	result, err := r.Put(ctx, models.NewHistorical("AAPL", points))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Written != len(points) || result.Failed != 0 {
+		t.Fatalf("got %+v, want all %d written", result, len(points))
+	}
+	if _, err := r.Put(ctx, models.NewHistorical("MSFT", points[:3])); err != nil {
+		t.Fatal(err)
+	}
+
+	historical, err := r.ForSymbol(ctx, "AAPL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(historical) != len(points) {
+		t.Fatalf("got %d days, want %d", len(historical), len(points))
+	}
+	for i, h := range historical {
+		if h.Symbol != "AAPL" || h.Date != points[i].Date || h.Close != points[i].Close {
+			t.Fatalf("day %d = %+v, want %s closing at %v", i, h, points[i].Date, points[i].Close)
+		}
+	}
+	if _, err := r.ForSymbol(ctx, "GOOG"); !IsNotFound(err) {
+		t.Fatalf("got error %v, want ErrorNotFound", err)
+	}
+
+	d.Inject(dynamodbtest.OpQuery, dynamodbtest.ErrorFault(awserr.New(db.ErrCodeInternalServerError, "internal error", nil)))
+	if _, err := r.ForSymbol(ctx, "AAPL"); err == nil {
+		t.Fatal("got no error, want the injected query error")
+	}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+)
+
+// StatsRepository - Advanced stats by symbol
+type StatsRepository interface {
+	Get(ctx context.Context, symbol string) (*models.Stats, error)
+	GetMany(ctx context.Context, symbols []string) ([]models.Stats, error)
+	Put(ctx context.Context, stats models.Stats) error
+}
+
+type statsRepository struct {
+	client dynamodbiface.DynamoDBAPI
+}
+
+func NewStatsRepository(client dynamodbiface.DynamoDBAPI) StatsRepository {
+	return &statsRepository{client: client}
+}
+
+// Get - Returns an ErrorNotFound if there are no stats for the symbol
+func (r *statsRepository) Get(ctx context.Context, symbol string) (*models.Stats, error) {
+	stats := &models.Stats{}
+	if err := getBySymbol(ctx, r.client, models.StatsTableName, symbol, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetMany - Returns the stats in the order of the symbols, symbols without stats are skipped
+func (r *statsRepository) GetMany(ctx context.Context, symbols []string) ([]models.Stats, error) {
+	stats := []models.Stats{}
+	if err := getManyBySymbol(ctx, r.client, models.StatsTableName, symbols, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *statsRepository) Put(ctx context.Context, stats models.Stats) error {
+	_, err := put(ctx, r.client, []models.Stats{stats})
+	return err
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+)
+
+// SymbolRepository - The symbols tracked by the platform, adding one triggers the symbol subscribers
+type SymbolRepository interface {
+	List(ctx context.Context) ([]string, error)
+	Put(ctx context.Context, symbols ...string) error
+}
+
+type symbolRepository struct {
+	client dynamodbiface.DynamoDBAPI
+}
+
+func NewSymbolRepository(client dynamodbiface.DynamoDBAPI) SymbolRepository {
+	return &symbolRepository{client: client}
+}
+
+// List - Scans every page of the Symbols table
+func (r *symbolRepository) List(ctx context.Context) ([]string, error) {
+	symbols := []string{}
+	var unmarshalErr error
+	err := r.client.ScanPagesWithContext(ctx, &db.ScanInput{
+		TableName: aws.String(models.SymbolsTableName),
+	}, func(page *db.ScanOutput, _ bool) bool {
+		items := []models.Symbol{}
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); unmarshalErr != nil {
+			return false
+		}
+		for _, item := range items {
+			symbols = append(symbols, item.Symbol)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	return symbols, nil
+}
+
+func (r *symbolRepository) Put(ctx context.Context, symbols ...string) error {
+	items := make([]models.Symbol, len(symbols))
+	for i, symbol := range symbols {
+		items[i] = models.Symbol{Symbol: symbol}
+	}
+	_, err := put(ctx, r.client, items)
+	return err
+}
@@ -0,0 +1,168 @@
+// Command tablegen builds the DynamoDB table definitions of the models registered in api/models
+//
+// Usage:
+//
+//	tg [flags] [command] [table ...]
+//
+// Commands:
+//
+//	cfn       print the CloudFormation Resources YAML (default)
+//	diff      print the differences between the definitions and the live tables, exits 2 on drift
+//	describe  print the live tables
+//	create    create the tables that do not exist yet
+//
+// Without table arguments every registered table is used
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	db "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+)
+
+var (
+	region   = flag.String("region", "us-west-2", "AWS region of the tables")
+	endpoint = flag.String("endpoint", "", "DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB Local")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [cfn|diff|describe|create] [table ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	command := "cfn"
+	args := flag.Args()
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	defs, err := selectDefinitions(args)
+	if err != nil {
+		exit(err)
+	}
+	switch command {
+	case "cfn":
+		err = dynamodbutil.WriteCloudFormation(os.Stdout, defs...)
+	case "diff":
+		var drift bool
+		drift, err = diff(newClient(), defs)
+		if err == nil && drift {
+			os.Exit(2)
+		}
+	case "describe":
+		err = describe(newClient(), defs)
+	case "create":
+		err = create(newClient(), defs)
+	default:
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		exit(err)
+	}
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+func newClient() dynamodbiface.DynamoDBAPI {
+	conf := &aws.Config{Region: aws.String(*region)}
+	if *endpoint != "" {
+		conf.Endpoint = aws.String(*endpoint)
+	}
+	sess, err := session.NewSession(conf)
+	if err != nil {
+		exit(err)
+	}
+	return db.New(sess)
+}
+
+// selectDefinitions - Returns the definitions of the named tables, or of every registered table if none are named
+func selectDefinitions(tables []string) ([]*dynamodbutil.TableDefinition, error) {
+	defs, err := models.Definitions()
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return defs, nil
+	}
+	byName := map[string]*dynamodbutil.TableDefinition{}
+	for _, def := range defs {
+		byName[aws.StringValue(def.CreateTableInput.TableName)] = def
+	}
+	selected := []*dynamodbutil.TableDefinition{}
+	for _, name := range tables {
+		def, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("Error: no model is registered for table %s", name)
+		}
+		selected = append(selected, def)
+	}
+	return selected, nil
+}
+
+func diff(ddbClient dynamodbiface.DynamoDBAPI, defs []*dynamodbutil.TableDefinition) (bool, error) {
+	drift := false
+	for _, def := range defs {
+		name := aws.StringValue(def.CreateTableInput.TableName)
+		live, err := dynamodbutil.DescribeLiveTable(ddbClient, name)
+		if err != nil {
+			return drift, err
+		}
+		diffs := dynamodbutil.DiffTable(def, live)
+		if len(diffs) == 0 {
+			fmt.Printf("%s: up to date\n", name)
+			continue
+		}
+		drift = true
+		fmt.Printf("%s:\n", name)
+		for _, d := range diffs {
+			fmt.Printf("  %s\n", d)
+		}
+	}
+	return drift, nil
+}
+
+func describe(ddbClient dynamodbiface.DynamoDBAPI, defs []*dynamodbutil.TableDefinition) error {
+	for _, def := range defs {
+		name := aws.StringValue(def.CreateTableInput.TableName)
+		live, err := dynamodbutil.DescribeLiveTable(ddbClient, name)
+		if err != nil {
+			return err
+		}
+		if live.Table == nil {
+			fmt.Printf("%s: does not exist\n", name)
+			continue
+		}
+		fmt.Printf("%s:\n%s\n%s\n%s\n", name, live.Table, live.TimeToLive, live.ContinuousBackups)
+	}
+	return nil
+}
+
+func create(ddbClient dynamodbiface.DynamoDBAPI, defs []*dynamodbutil.TableDefinition) error {
+	for _, def := range defs {
+		name := aws.StringValue(def.CreateTableInput.TableName)
+		live, err := dynamodbutil.DescribeLiveTable(ddbClient, name)
+		if err != nil {
+			return err
+		}
+		if live.Table != nil {
+			fmt.Printf("%s: already exists\n", name)
+			continue
+		}
+		if err := dynamodbutil.CreateTableFromDefinition(ddbClient, def); err != nil {
+			return err
+		}
+		fmt.Printf("%s: created\n", name)
+	}
+	return nil
+}
@@ -1,19 +1,18 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
-	iex "github.com/goinvest/iexcloud/v2"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	ddb "github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 
+	"github.com/mcclurejt/mrkt-backend/api/repository"
 	"github.com/mcclurejt/mrkt-backend/serverless/services/lambda-api/util"
 	"github.com/sirupsen/logrus"
 )
@@ -24,8 +23,8 @@ import (
 // https://serverless.com/framework/docs/providers/aws/events/apigateway/#lambda-proxy-integration
 
 var (
-	ddbClient dynamodbiface.DynamoDBAPI
-	log       *logrus.Logger
+	companyRepository repository.CompanyRepository
+	log               *logrus.Logger
 )
 
 func init() {
@@ -35,11 +34,11 @@ func init() {
 	if err != nil {
 		return
 	}
-	ddbClient = ddb.New(awsSession)
+	companyRepository = repository.NewCompanyRepository(ddb.New(awsSession))
 	log = logrus.New()
 }
 
-func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	log := log.WithFields(logrus.Fields{"path": request.Path, "method": request.HTTPMethod})
 	if request.HTTPMethod != http.MethodGet {
 		err := util.NewErrorMethodNotImplemented(request.HTTPMethod)
@@ -53,21 +52,15 @@ func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyRespo
 	symbol = strings.ToUpper(symbol)
 	// get historical data for symbol
 	log.Infof("Retrieving Company Data for %s...", symbol)
-	out, err := ddbClient.GetItem(
-		&ddb.GetItemInput{
-			TableName: aws.String("Company"),
-			Key: map[string]*ddb.AttributeValue{
-				"Symbol": {S: aws.String(symbol)},
-			},
-		})
-	// parse the response object
-	company := iex.Company{}
-	err = dynamodbattribute.UnmarshalMap(out.Item, &company)
+	company, err := companyRepository.Get(ctx, symbol)
+	if repository.IsNotFound(err) {
+		err = util.NewErrorDataNotFoundForSymbol("Company", symbol)
+	}
 	if err != nil {
 		return util.ErrorToGatewayResponse(err)
 	}
 	log.Infof("Retrieved company data for symbol %s", symbol)
-	return util.ObjectToGatewayResponse(company)
+	return util.ObjectToGatewayResponse(company.IEX())
 }
 
 func main() {
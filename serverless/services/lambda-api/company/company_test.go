@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	iex "github.com/goinvest/iexcloud/v2"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil/dynamodbtest"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+	"github.com/mcclurejt/mrkt-backend/api/repository"
+)
+
+func TestHandler(t *testing.T) {
+	d, err := dynamodbtest.NewFromModels(models.Company{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	companyRepository = repository.NewCompanyRepository(d)
+	stored := iex.Company{Name: "Apple Inc.", Exchange: "NASDAQ", Sector: "Electronic Technology", Tags: []string{"Telecommunications Equipment"}}
+	if err := companyRepository.Put(context.Background(), models.NewCompany("AAPL", stored)); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := handler(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"symbol": "aapl"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	company := iex.Company{}
+	if err := json.Unmarshal([]byte(res.Body), &company); err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 || company.Symbol != "AAPL" || company.Name != stored.Name || company.Sector != stored.Sector || len(company.Tags) != 1 {
+		t.Fatalf("got %d %s, want the stored AAPL company", res.StatusCode, res.Body)
+	}
+
+	if _, err := handler(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"symbol": "msft"},
+	}); err == nil {
+		t.Fatal("got no error, want data not found for MSFT")
+	}
+
+	if res, err := handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "POST"}); err == nil || res.StatusCode != 501 {
+		t.Fatalf("got %d with error %v, want POST rejected", res.StatusCode, err)
+	}
+
+	d.Inject(dynamodbtest.OpGetItem, dynamodbtest.ThrottleFault())
+	res, err = handler(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"symbol": "aapl"},
+	})
+	if err == nil || res.StatusCode == 200 {
+		t.Fatalf("got %d with error %v, want the throttling error", res.StatusCode, err)
+	}
+}
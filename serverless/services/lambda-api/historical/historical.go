@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -10,11 +11,9 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
 	ddb "github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 
+	"github.com/mcclurejt/mrkt-backend/api/repository"
 	"github.com/mcclurejt/mrkt-backend/serverless/services/lambda-api/util"
 	"github.com/sirupsen/logrus"
 )
@@ -30,8 +29,8 @@ type HistoricalWithSymbol struct {
 }
 
 var (
-	ddbClient dynamodbiface.DynamoDBAPI
-	log       *logrus.Logger
+	historicalRepository repository.HistoricalRepository
+	log                  *logrus.Logger
 )
 
 func init() {
@@ -41,43 +40,26 @@ func init() {
 	if err != nil {
 		return
 	}
-	ddbClient = ddb.New(awsSession)
+	historicalRepository = repository.NewHistoricalRepository(ddb.New(awsSession))
 	log = logrus.New()
 }
 
-func historicalForSymbol(symbol string) ([]HistoricalWithSymbol, error) {
-	historical := []HistoricalWithSymbol{}
-	err := ddbClient.QueryPages(&ddb.QueryInput{
-		TableName:              aws.String("Historical"),
-		KeyConditionExpression: aws.String("#pk = :s"),
-		ExpressionAttributeNames: map[string]*string{
-			"#pk": aws.String("Symbol"),
-		},
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":s": {
-				S: aws.String(symbol),
-			},
-		},
-	}, func(page *dynamodb.QueryOutput, _ bool) bool {
-		h := []HistoricalWithSymbol{}
-		err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &h)
-		if err != nil {
-			log.Errorf("Unable to unmarshal AWS data: err = %v", err)
-			return true
-		}
-		historical = append(historical, h...)
-		return true
-	})
+func historicalForSymbol(ctx context.Context, symbol string) ([]HistoricalWithSymbol, error) {
+	stored, err := historicalRepository.ForSymbol(ctx, symbol)
+	if repository.IsNotFound(err) {
+		return nil, util.NewErrorDataNotFoundForSymbol("Historical", symbol)
+	}
 	if err != nil {
-		return historical, err
+		return nil, err
 	}
-	if len(historical) == 0 {
-		return historical, util.NewErrorDataNotFoundForSymbol("Historical", symbol)
+	historical := make([]HistoricalWithSymbol, len(stored))
+	for i, h := range stored {
+		historical[i] = HistoricalWithSymbol{HistoricalDataPoint: h.IEX(), Symbol: h.Symbol}
 	}
 	return historical, nil
 }
 
-func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	log := log.WithFields(logrus.Fields{"path": request.Path, "method": request.HTTPMethod})
 	if request.HTTPMethod != http.MethodGet {
 		err := util.NewErrorMethodNotImplemented(request.HTTPMethod)
@@ -91,7 +73,7 @@ func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyRespo
 	symbol = strings.ToUpper(symbol)
 	// get historical data for symbol
 	log.Infof("Retrieving Historical Data for %s...", symbol)
-	historical, err := historicalForSymbol(symbol)
+	historical, err := historicalForSymbol(ctx, symbol)
 	if err != nil {
 		return util.ErrorToGatewayResponse(err)
 	}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	iex "github.com/goinvest/iexcloud/v2"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil/dynamodbtest"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+	"github.com/mcclurejt/mrkt-backend/api/repository"
+)
+
+func TestHandler(t *testing.T) {
+	d, err := dynamodbtest.NewFromModels(models.Historical{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	historicalRepository = repository.NewHistoricalRepository(d)
+	stored := []iex.HistoricalDataPoint{
+		{Date: "2020-01-02", Open: 10, Close: 11},
+		{Date: "2020-01-03", Open: 11, Close: 12},
+	}
+	if _, err := historicalRepository.Put(context.Background(), models.NewHistorical("AAPL", stored)); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := handler(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"symbol": "aapl"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	historical := []HistoricalWithSymbol{}
+	if err := json.Unmarshal([]byte(res.Body), &historical); err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 || len(historical) != 2 || historical[1].Symbol != "AAPL" || historical[1].Close != 12 {
+		t.Fatalf("got %d %s, want both AAPL days", res.StatusCode, res.Body)
+	}
+
+	if _, err := handler(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"symbol": "msft"},
+	}); err == nil {
+		t.Fatal("got no error, want data not found for MSFT")
+	}
+
+	d.Inject(dynamodbtest.OpQuery, dynamodbtest.ThrottleFault())
+	res, err = handler(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"symbol": "aapl"},
+	})
+	if err == nil || res.StatusCode == 200 {
+		t.Fatalf("got %d with error %v, want the throttling error", res.StatusCode, err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+	"github.com/mcclurejt/mrkt-backend/api/indicators"
+	"github.com/mcclurejt/mrkt-backend/config"
+
+	"github.com/mcclurejt/mrkt-backend/serverless/services/lambda-api/util"
+	"github.com/sirupsen/logrus"
+)
+
+// Response is of type APIGatewayProxyResponse since we're leveraging the
+// AWS Lambda Proxy Request functionality (default behavior)
+//
+// https://serverless.com/framework/docs/providers/aws/events/apigateway/#lambda-proxy-integration
+
+const (
+	nuplRegimesIndicator = "nupl-regimes"
+	mvrvZScoreIndicator  = "mvrv-zscore"
+)
+
+type NuplRegimesResponse struct {
+	Asset   string                     `json:"asset"`
+	Points  []*indicators.RegimePoint  `json:"points"`
+	Changes []*indicators.RegimeChange `json:"changes"`
+}
+
+type MVRVZScoreResponse struct {
+	Asset  string                    `json:"asset"`
+	Points []*indicators.ZScorePoint `json:"points"`
+}
+
+var (
+	glassnodeClient *glassnode.GlassNodeClient
+	log             *logrus.Logger
+)
+
+// glassnodeOptions - Invalid assets and intervals are rejected against the endpoint catalogue without spending a request
+var glassnodeOptions = []glassnode.ClientOption{glassnode.WithAPIKeyHeader(), glassnode.WithCatalogValidation()}
+
+func init() {
+	conf := config.New() //env
+	// warm lambdas reuse the cache between invocations
+	glassnodeClient = glassnode.NewGlassNodeClient(conf.Api.GlassNodeAPIKey, append(glassnodeOptions, glassnode.WithCache(glassnode.NewMemoryCache()))...)
+	log = logrus.New()
+}
+
+func indicator(ctx context.Context, asset string, name string, params map[string]string) (interface{}, error) {
+	since, until, err := util.ParseRangeParameters(params)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case nuplRegimesIndicator:
+		options := glassnode.DefaultNetUnrealizedProfitLossOptions()
+		options.Asset = asset
+		options.Since = since
+		options.Until = until
+		points, err := indicators.NuplRegimes(ctx, glassnodeClient, options)
+		if err != nil {
+			return nil, err
+		}
+		return &NuplRegimesResponse{Asset: asset, Points: points, Changes: indicators.RegimeChanges(points)}, nil
+	case mvrvZScoreIndicator:
+		options := glassnode.DefaultMetricOptions("")
+		options.Asset = asset
+		options.Since = since
+		options.Until = until
+		points, err := indicators.FetchMVRVZScore(ctx, glassnodeClient, options)
+		if err != nil {
+			return nil, err
+		}
+		return &MVRVZScoreResponse{Asset: asset, Points: points}, nil
+	default:
+		return nil, util.NewErrorInvalidParameter("indicator", name)
+	}
+}
+
+func handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	log := log.WithFields(logrus.Fields{"path": request.Path, "method": request.HTTPMethod})
+	if request.HTTPMethod != http.MethodGet {
+		err := util.NewErrorMethodNotImplemented(request.HTTPMethod)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 501,
+			Body:       util.EncodeStringAsBody(err.Error()),
+		}, err
+	}
+	// extract asset and indicator from the path
+	asset := strings.ToUpper(request.PathParameters["asset"])
+	name := strings.ToLower(request.PathParameters["indicator"])
+	// compute the indicator for asset
+	log.Infof("Computing %s for %s...", name, asset)
+	result, err := indicator(ctx, asset, name, request.QueryStringParameters)
+	if err != nil {
+		log.Errorf("Unable to compute %s for %s: %v", name, asset, err)
+		return util.GlassnodeErrorToGatewayResponse(err)
+	}
+	log.Infof("Computed %s for %s", name, asset)
+	return util.ObjectToGatewayResponse(result)
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+	"github.com/mcclurejt/mrkt-backend/config"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	ddb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/mcclurejt/mrkt-backend/serverless/services/lambda-api/util"
+	"github.com/sirupsen/logrus"
+)
+
+// Response is of type APIGatewayProxyResponse since we're leveraging the
+// AWS Lambda Proxy Request functionality (default behavior)
+//
+// https://serverless.com/framework/docs/providers/aws/events/apigateway/#lambda-proxy-integration
+
+// storedInterval is the only interval persisted by the onchain subscriber,
+// any other interval is always fetched from glassnode
+const storedInterval = glassnode.Interval24h
+
+var (
+	glassnodeClient *glassnode.GlassNodeClient
+	ddbClient       dynamodbiface.DynamoDBAPI
+	log             *logrus.Logger
+)
+
+// glassnodeOptions - Invalid assets and intervals are rejected against the endpoint catalogue without spending a request
+var glassnodeOptions = []glassnode.ClientOption{glassnode.WithAPIKeyHeader(), glassnode.WithCatalogValidation()}
+
+func init() {
+	conf := config.New() //env
+	glassnodeClient = glassnode.NewGlassNodeClient(conf.Api.GlassNodeAPIKey, glassnodeOptions...)
+	awsSession, err := session.NewSession(&aws.Config{
+		Region: aws.String("us-west-2")},
+	)
+	if err != nil {
+		return
+	}
+	ddbClient = ddb.New(awsSession)
+	log = logrus.New()
+}
+
+func storedSeries(ctx context.Context, options *glassnode.MetricOptions, metric glassnode.GlassNodeRouteName) ([]*glassnode.MetricEntry, error) {
+	condition := "#pk = :s"
+	values := map[string]*ddb.AttributeValue{
+		":s": {
+			S: aws.String(models.SeriesKey(options.Asset, metric)),
+		},
+	}
+	names := map[string]*string{
+		"#pk": aws.String("Series"),
+	}
+	since, until := int64(0), time.Now().Unix()
+	if !options.Since.IsZero() {
+		since = options.Since.Unix()
+	}
+	if !options.Until.IsZero() {
+		until = options.Until.Unix()
+	}
+	condition += " AND #ts BETWEEN :since AND :until"
+	names["#ts"] = aws.String("Timestamp")
+	values[":since"] = &ddb.AttributeValue{N: aws.String(strconv.FormatInt(since, 10))}
+	values[":until"] = &ddb.AttributeValue{N: aws.String(strconv.FormatInt(until, 10))}
+
+	entries := []*glassnode.MetricEntry{}
+	var unmarshalErr error
+	err := ddbClient.QueryPagesWithContext(ctx, &ddb.QueryInput{
+		TableName:                 aws.String(models.OnChainTableName),
+		KeyConditionExpression:    aws.String(condition),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}, func(page *ddb.QueryOutput, _ bool) bool {
+		stored := []*models.OnChainEntry{}
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &stored); unmarshalErr != nil {
+			return false
+		}
+		for _, s := range stored {
+			entries = append(entries, s.MetricEntry())
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	return entries, nil
+}
+
+func onChainSeries(ctx context.Context, asset string, metric glassnode.GlassNodeRouteName, params map[string]string) ([]*glassnode.MetricEntry, error) {
+	path, ok := glassnodeClient.MetricPath(metric)
+	if !ok {
+		return nil, util.NewErrorInvalidParameter("metric", string(metric))
+	}
+	options := glassnode.DefaultMetricOptions(path)
+	options.Asset = asset
+	if interval := params["interval"]; interval != "" {
+		options.Interval = glassnode.Interval(interval)
+		if options.Interval.Duration() == 0 {
+			return nil, util.NewErrorInvalidParameter("interval", interval)
+		}
+	}
+	var err error
+	if options.Since, options.Until, err = util.ParseRangeParameters(params); err != nil {
+		return nil, err
+	}
+	if options.Interval != storedInterval {
+		return liveSeries(ctx, options)
+	}
+	// Prefer the stored series, falling back to glassnode when the start of the range is missing
+	// and fetching only the points after the newest stored one when the end is missing
+	series := models.SeriesKey(asset, metric)
+	entries, err := storedSeries(ctx, options, metric)
+	if err != nil {
+		return nil, err
+	}
+	interval := options.Interval.Duration()
+	if len(entries) == 0 || (!options.Since.IsZero() && entries[0].Timestamp > firstExpected(options.Since, interval)) {
+		log.Infof("Stored datapoints for %s do not cover the start of the range, falling back to glassnode", series)
+		return liveSeries(ctx, options)
+	}
+	until := options.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	last := entries[len(entries)-1].Timestamp
+	if last >= latestExpected(until, interval, time.Now()) {
+		return entries, nil
+	}
+	log.Infof("Stored datapoints for %s end at %d, fetching the rest from glassnode", series, last)
+	gap := *options
+	gap.Since = time.Unix(last+1, 0)
+	rest, err := liveSeries(ctx, &gap)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range rest {
+		if e.Timestamp > last {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// liveSeries - Fetches the series from glassnode, leaving out points without a value like the subscriber does
+func liveSeries(ctx context.Context, options *glassnode.MetricOptions) ([]*glassnode.MetricEntry, error) {
+	points, err := glassnodeClient.Metrics.GetPoints(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	entries, _, err := glassnode.EntriesWithValues(points)
+	return entries, err
+}
+
+// firstExpected - Timestamp of the first point of the range, points are aligned to their interval
+func firstExpected(since time.Time, interval time.Duration) int64 {
+	t := since.Truncate(interval)
+	if t.Before(since) {
+		t = t.Add(interval)
+	}
+	return t.Unix()
+}
+
+// latestExpected - Timestamp of the last point of a range ending at until, a point only exists once its interval is over
+func latestExpected(until time.Time, interval time.Duration, now time.Time) int64 {
+	t := until.Truncate(interval)
+	for t.Add(interval).After(now) {
+		t = t.Add(-interval)
+	}
+	return t.Unix()
+}
+
+func handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	log := log.WithFields(logrus.Fields{"path": request.Path, "method": request.HTTPMethod})
+	if request.HTTPMethod != http.MethodGet {
+		err := util.NewErrorMethodNotImplemented(request.HTTPMethod)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 501,
+			Body:       util.EncodeStringAsBody(err.Error()),
+		}, err
+	}
+	// extract asset and metric from the path
+	asset := strings.ToUpper(request.PathParameters["asset"])
+	metric := glassnode.GlassNodeRouteName(strings.ToLower(request.PathParameters["metric"]))
+	// get on-chain data for asset
+	log.Infof("Retrieving %s data for %s...", metric, asset)
+	entries, err := onChainSeries(ctx, asset, metric, request.QueryStringParameters)
+	if err != nil {
+		log.Errorf("Unable to retrieve %s data for %s: %v", metric, asset, err)
+		return util.GlassnodeErrorToGatewayResponse(err)
+	}
+	log.Infof("Retrieved %d datapoints of %s for %s", len(entries), metric, asset)
+	return util.ObjectToGatewayResponse(entries)
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil/dynamodbtest"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode/glassnodetest"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+)
+
+// setup - Stores the first n NUPL points of the BTC fixture series and points the clients at the fakes
+func setup(t *testing.T, n int) (*glassnodetest.Server, *dynamodbtest.DB) {
+	t.Helper()
+	server := glassnodetest.NewServer(nil)
+	t.Cleanup(server.Close)
+	glassnodeClient = server.Client(glassnodeOptions...)
+	d, err := dynamodbtest.NewFromModels(models.OnChainEntry{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddbClient = d
+	points := server.Fixtures.Points(glassnodetest.NuplPath, glassnode.BTC)[:n]
+	entries, _, err := glassnode.EntriesWithValues(points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := dynamodbutil.NewBatchWriter(d)
+	if err := w.PutItems(models.NewOnChainEntries(glassnode.BTC, glassnode.NuplRouteName, entries)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return server, d
+}
+
+func get(t *testing.T, params map[string]string) (events.APIGatewayProxyResponse, []*glassnode.MetricEntry) {
+	t.Helper()
+	res, err := handler(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:            "GET",
+		PathParameters:        map[string]string{"asset": "btc", "metric": string(glassnode.NuplRouteName)},
+		QueryStringParameters: params,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []*glassnode.MetricEntry{}
+	if res.StatusCode == http.StatusOK {
+		if err := json.Unmarshal([]byte(res.Body), &entries); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return res, entries
+}
+
+func day(i int) string {
+	return fmt.Sprint(glassnodetest.Start.AddDate(0, 0, i).Unix())
+}
+
+func TestHandlerUsesStoredSeries(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored int
+		params map[string]string
+		want   int
+		live   bool
+	}{
+		{name: "stored span covers the range", stored: 20, params: map[string]string{"since": day(0), "until": day(9)}, want: 10},
+		{name: "only the gap after the stored points is fetched", stored: 20, params: map[string]string{"since": day(5), "until": day(29)}, want: 25, live: true},
+		{name: "open ended range fetches the gap", stored: 20, params: map[string]string{}, want: glassnodetest.DailyPoints, live: true},
+		{name: "start of the range is not stored", stored: 20, params: map[string]string{"since": day(-10), "until": day(9)}, want: 10, live: true},
+		{name: "nothing stored", stored: 0, params: map[string]string{"since": day(0), "until": day(9)}, want: 10, live: true},
+		{name: "other intervals are always live", stored: 20, params: map[string]string{"interval": "1h", "since": day(0), "until": day(1)}, want: 2, live: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := setup(t, tt.stored)
+			res, entries := get(t, tt.params)
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("got %d %s, want 200", res.StatusCode, res.Body)
+			}
+			if len(entries) != tt.want {
+				t.Fatalf("got %d entries, want %d", len(entries), tt.want)
+			}
+			for i := 1; i < len(entries); i++ {
+				if entries[i].Timestamp <= entries[i-1].Timestamp {
+					t.Fatalf("got timestamp %d after %d, want the entries in order without duplicates", entries[i].Timestamp, entries[i-1].Timestamp)
+				}
+			}
+			if n := server.Requests(glassnodetest.NuplPath); (n > 0) != tt.live {
+				t.Fatalf("got %d requests to glassnode, want requests %v", n, tt.live)
+			}
+		})
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		fault  *glassnodetest.Fault
+		params map[string]string
+		metric string
+		want   int
+	}{
+		{name: "unknown metric", metric: "unknown", want: http.StatusBadRequest},
+		{name: "invalid interval", params: map[string]string{"interval": "2d"}, want: http.StatusBadRequest},
+		{name: "interval not in the catalogue", params: map[string]string{"interval": "10m"}, want: http.StatusBadRequest},
+		{name: "invalid range", params: map[string]string{"since": day(2), "until": day(1)}, want: http.StatusBadRequest},
+		{name: "tier restricted", fault: &glassnodetest.Fault{StatusCode: http.StatusForbidden}, want: http.StatusForbidden},
+		{name: "rate limited", fault: &glassnodetest.Fault{StatusCode: http.StatusTooManyRequests}, want: http.StatusTooManyRequests},
+		{name: "key rejected", fault: &glassnodetest.Fault{StatusCode: http.StatusUnauthorized}, want: http.StatusBadGateway},
+		{name: "bad request", fault: &glassnodetest.Fault{StatusCode: http.StatusBadRequest}, want: http.StatusBadRequest},
+		{name: "upstream failure", fault: &glassnodetest.Fault{StatusCode: http.StatusInternalServerError}, want: http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := setup(t, 0)
+			if tt.fault != nil {
+				server.Inject(glassnodetest.AnyPath, *tt.fault)
+			}
+			metric := tt.metric
+			if metric == "" {
+				metric = string(glassnode.NuplRouteName)
+			}
+			res, err := handler(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:            "GET",
+				PathParameters:        map[string]string{"asset": "btc", "metric": metric},
+				QueryStringParameters: tt.params,
+			})
+			if err != nil {
+				t.Fatalf("got error %v, want it mapped to a response", err)
+			}
+			if res.StatusCode != tt.want {
+				t.Fatalf("got %d %s, want %d", res.StatusCode, res.Body, tt.want)
+			}
+			// invalid requests are rejected before anything is sent to glassnode
+			if tt.fault == nil && server.Requests(glassnodetest.NuplPath) != 0 {
+				t.Fatalf("got %d requests to glassnode, want 0", server.Requests(glassnodetest.NuplPath))
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -11,9 +12,9 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	ddb "github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 
+	"github.com/mcclurejt/mrkt-backend/api/models"
+	"github.com/mcclurejt/mrkt-backend/api/repository"
 	"github.com/mcclurejt/mrkt-backend/serverless/services/lambda-api/util"
 	"github.com/sirupsen/logrus"
 )
@@ -33,9 +34,22 @@ type StatsWithSymbol struct {
 	Week52LowDate    string `json:"Week52LowDate"`
 }
 
+// NewStatsWithSymbol - Shapes the stored stats as returned by the API, dates are kept in the stored 2006-01-02 layout
+func NewStatsWithSymbol(stats *models.Stats) StatsWithSymbol {
+	return StatsWithSymbol{
+		AdvancedStats:    stats.IEX(),
+		Symbol:           stats.Symbol,
+		NextDividendDate: stats.NextDividendDate,
+		ExDividendDate:   stats.ExDividendDate,
+		NextEarningsDate: stats.NextEarningsDate,
+		Week52HighDate:   stats.Week52HighDate,
+		Week52LowDate:    stats.Week52LowDate,
+	}
+}
+
 var (
-	ddbClient dynamodbiface.DynamoDBAPI
-	log       *logrus.Logger
+	statsRepository repository.StatsRepository
+	log             *logrus.Logger
 )
 
 func init() {
@@ -45,11 +59,11 @@ func init() {
 	if err != nil {
 		return
 	}
-	ddbClient = ddb.New(awsSession)
+	statsRepository = repository.NewStatsRepository(ddb.New(awsSession))
 	log = logrus.New()
 }
 
-func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	log := log.WithFields(logrus.Fields{"path": request.Path, "method": request.HTTPMethod})
 	if request.HTTPMethod != http.MethodGet {
 		err := util.NewErrorMethodNotImplemented(request.HTTPMethod)
@@ -63,21 +77,15 @@ func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyRespo
 	symbol = strings.ToUpper(symbol)
 	// get historical data for symbol
 	log.Infof("Retrieving Stats for %s...", symbol)
-	out, err := ddbClient.GetItem(
-		&ddb.GetItemInput{
-			TableName: aws.String("Stats"),
-			Key: map[string]*ddb.AttributeValue{
-				"Symbol": {S: aws.String(symbol)},
-			},
-		})
-	// parse the response object
-	stats := StatsWithSymbol{}
-	err = dynamodbattribute.UnmarshalMap(out.Item, &stats)
+	stats, err := statsRepository.Get(ctx, symbol)
+	if repository.IsNotFound(err) {
+		err = util.NewErrorDataNotFoundForSymbol("Stats", symbol)
+	}
 	if err != nil {
 		return util.ErrorToGatewayResponse(err)
 	}
 	log.Infof("Retrieved stats for symbol %s", symbol)
-	return util.ObjectToGatewayResponse(stats)
+	return util.ObjectToGatewayResponse(NewStatsWithSymbol(stats))
 }
 
 func main() {
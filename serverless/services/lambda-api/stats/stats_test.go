@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil/dynamodbtest"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+	"github.com/mcclurejt/mrkt-backend/api/repository"
+)
+
+func TestHandler(t *testing.T) {
+	d, err := dynamodbtest.NewFromModels(models.Stats{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statsRepository = repository.NewStatsRepository(d)
+	stored := models.Stats{Symbol: "AAPL", MarketCap: 2e12, PERatio: 30, NextEarningsDate: "2020-10-29", Week52HighDate: "2020-09-02"}
+	if err := statsRepository.Put(context.Background(), stored); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := handler(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"symbol": "aapl"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// decoded as a map, the embedded IEX stats use the same field names with other casing and types
+	stats := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(res.Body), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 || stats["Symbol"] != "AAPL" || stats["marketCap"] != stored.MarketCap || stats["peRatio"] != stored.PERatio {
+		t.Fatalf("got %d %s, want the stored AAPL stats", res.StatusCode, res.Body)
+	}
+	if stats["NextEarningsDate"] != stored.NextEarningsDate || stats["Week52HighDate"] != stored.Week52HighDate {
+		t.Fatalf("got dates %v and %v, want them in the stored layout", stats["NextEarningsDate"], stats["Week52HighDate"])
+	}
+
+	if _, err := handler(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"symbol": "msft"},
+	}); err == nil {
+		t.Fatal("got no error, want data not found for MSFT")
+	}
+
+	if res, err := handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "POST"}); err == nil || res.StatusCode != 501 {
+		t.Fatalf("got %d with error %v, want POST rejected", res.StatusCode, err)
+	}
+
+	d.Inject(dynamodbtest.OpGetItem, dynamodbtest.ThrottleFault())
+	res, err = handler(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"symbol": "aapl"},
+	})
+	if err == nil || res.StatusCode == 200 {
+		t.Fatalf("got %d with error %v, want the throttling error", res.StatusCode, err)
+	}
+}
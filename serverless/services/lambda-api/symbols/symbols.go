@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
@@ -10,8 +11,8 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	ddb "github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 
+	"github.com/mcclurejt/mrkt-backend/api/repository"
 	"github.com/mcclurejt/mrkt-backend/serverless/services/lambda-api/util"
 	"github.com/sirupsen/logrus"
 )
@@ -22,8 +23,8 @@ import (
 // https://serverless.com/framework/docs/providers/aws/events/apigateway/#lambda-proxy-integration
 
 var (
-	ddbClient dynamodbiface.DynamoDBAPI
-	log       *logrus.Logger
+	symbolRepository repository.SymbolRepository
+	log              *logrus.Logger
 )
 
 func init() {
@@ -33,31 +34,11 @@ func init() {
 	if err != nil {
 		return
 	}
-	ddbClient = ddb.New(awsSession)
+	symbolRepository = repository.NewSymbolRepository(ddb.New(awsSession))
 	log = logrus.New()
 }
 
-func listSymbols() ([]string, error) {
-	symbols := []string{}
-	for {
-		symbolBatch, err := ddbClient.Scan(&ddb.ScanInput{TableName: aws.String("Symbols")})
-		if err != nil {
-			return []string{}, err
-		}
-		for _, attributeMap := range symbolBatch.Items {
-			log.Infof("%v", attributeMap)
-			if symbol, ok := attributeMap["Symbol"]; ok && symbol.S != nil {
-				symbols = append(symbols, *symbol.S)
-			}
-		}
-		if len(symbolBatch.LastEvaluatedKey) == 0 {
-			break
-		}
-	}
-	return symbols, nil
-}
-
-func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	log := log.WithFields(logrus.Fields{"path": request.Path, "method": request.HTTPMethod})
 	// check http method
 	if request.HTTPMethod != http.MethodGet {
@@ -69,7 +50,7 @@ func handler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyRespo
 	}
 	// get list of symbols
 	log.Info("Retrieving Symbols...")
-	symbols, err := listSymbols()
+	symbols, err := symbolRepository.List(ctx)
 	if err != nil {
 		return util.ErrorToGatewayResponse(err)
 	}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil/dynamodbtest"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+	"github.com/mcclurejt/mrkt-backend/api/repository"
+)
+
+func TestHandler(t *testing.T) {
+	d, err := dynamodbtest.NewFromModels(models.Symbol{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbolRepository = repository.NewSymbolRepository(d)
+
+	res, err := handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 || res.Body != "[]" {
+		t.Fatalf("got %d %s, want an empty list", res.StatusCode, res.Body)
+	}
+
+	if err := symbolRepository.Put(context.Background(), "AAPL", "MSFT", "TSLA"); err != nil {
+		t.Fatal(err)
+	}
+	res, err = handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbols := []string{}
+	if err := json.Unmarshal([]byte(res.Body), &symbols); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(symbols)
+	if res.StatusCode != 200 || len(symbols) != 3 || symbols[0] != "AAPL" || symbols[2] != "TSLA" {
+		t.Fatalf("got %d %s, want every stored symbol", res.StatusCode, res.Body)
+	}
+
+	if res, err := handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "POST"}); err == nil || res.StatusCode != 501 {
+		t.Fatalf("got %d with error %v, want POST rejected", res.StatusCode, err)
+	}
+
+	d.Inject(dynamodbtest.OpScan, dynamodbtest.ThrottleFault())
+	res, err = handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err == nil || res.StatusCode == 200 {
+		t.Fatalf("got %d with error %v, want the throttling error", res.StatusCode, err)
+	}
+}
@@ -26,3 +26,16 @@ func (e *ErrorMethodNotImplemented) Error() string {
 func NewErrorMethodNotImplemented(method string) *ErrorMethodNotImplemented {
 	return &ErrorMethodNotImplemented{Method: method}
 }
+
+type ErrorInvalidParameter struct {
+	Name  string
+	Value string
+}
+
+func (e *ErrorInvalidParameter) Error() string {
+	return fmt.Sprintf("ERROR: '%s' is not a valid value for %s", e.Value, e.Name)
+}
+
+func NewErrorInvalidParameter(name string, value string) *ErrorInvalidParameter {
+	return &ErrorInvalidParameter{Name: name, Value: value}
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+)
+
+// GlassnodeErrorToGatewayResponse - Maps errors of routes backed by the glassnode client to a status the caller can act on
+// Errors caused by the request are 4xx, failures of the upstream API, including our own key being rejected, are 502
+// The error is not returned for mapped statuses, as API Gateway would replace the response with its own 502
+func GlassnodeErrorToGatewayResponse(err error) (events.APIGatewayProxyResponse, error) {
+	var invalidParameter *ErrorInvalidParameter
+	var unsupportedOption *glassnode.UnsupportedOptionError
+	var routeNotRecognized *glassnode.RouteNotRecognizedError
+	var invalidRange *glassnode.InvalidRangeError
+	var apiErr *glassnode.APIError
+	var status int
+	switch {
+	case errors.As(err, &invalidParameter), errors.As(err, &unsupportedOption), errors.As(err, &routeNotRecognized), errors.As(err, &invalidRange):
+		status = http.StatusBadRequest
+	case glassnode.IsTierRestricted(err):
+		status = http.StatusForbidden
+	case glassnode.IsRateLimited(err):
+		status = http.StatusTooManyRequests
+	case errors.As(err, &apiErr):
+		status = http.StatusBadGateway
+		if apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusUnprocessableEntity {
+			status = http.StatusBadRequest
+		}
+	default:
+		return ErrorToGatewayResponse(err)
+	}
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       EncodeStringAsBody(err.Error()),
+	}, nil
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"strconv"
+	"time"
+)
+
+// ParseTimeParameter accepts either a unix timestamp in seconds or a date
+// formatted as 2006-01-02 / RFC3339, an empty value returns the zero time
+func ParseTimeParameter(name string, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, NewErrorInvalidParameter(name, value)
+}
+
+// ParseRangeParameters parses the since and until query parameters, until
+// must come after since when both are given
+func ParseRangeParameters(params map[string]string) (time.Time, time.Time, error) {
+	since, err := ParseTimeParameter("since", params["since"])
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	until, err := ParseTimeParameter("until", params["until"])
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		return time.Time{}, time.Time{}, NewErrorInvalidParameter("until", params["until"])
+	}
+	return since, until, nil
+}
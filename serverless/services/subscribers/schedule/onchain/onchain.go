@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+	"github.com/mcclurejt/mrkt-backend/config"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	ddb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OnChainEvent is the input of the cloudwatch schedule, any field left empty
+// falls back to the defaults below
+type OnChainEvent struct {
+	Assets  []string                       `json:"assets"`
+	Metrics []glassnode.GlassNodeRouteName `json:"metrics"`
+}
+
+var (
+	defaultAssets  = []string{glassnode.BTC, glassnode.ETH}
+	defaultMetrics = []glassnode.GlassNodeRouteName{
+		glassnode.NuplRouteName,
+		glassnode.SoprRouteName,
+		glassnode.MvrvRouteName,
+		glassnode.ExchangeBalanceRouteName,
+		glassnode.ActiveAddressesRouteName,
+		glassnode.MarketCapRouteName,
+		glassnode.RealizedCapRouteName,
+	}
+)
+
+var (
+	glassnodeClient *glassnode.GlassNodeClient
+	ddbClient       dynamodbiface.DynamoDBAPI
+	log             *logrus.Logger
+)
+
+// glassnodeOptions - Invalid assets and intervals are rejected against the endpoint catalogue without spending a request
+var glassnodeOptions = []glassnode.ClientOption{glassnode.WithAPIKeyHeader(), glassnode.WithCatalogValidation()}
+
+func init() {
+	conf := config.New() //env
+	glassnodeClient = glassnode.NewGlassNodeClient(conf.Api.GlassNodeAPIKey, glassnodeOptions...)
+	awsSession, err := session.NewSession(&aws.Config{
+		Region: aws.String("us-west-2")},
+	)
+	if err != nil {
+		return
+	}
+	ddbClient = ddb.New(awsSession)
+	log = logrus.New()
+}
+
+// latestTimestamp returns the newest stored timestamp for the series, or 0 if
+// nothing has been stored yet
+func latestTimestamp(ctx context.Context, series string) (int64, error) {
+	out, err := ddbClient.QueryWithContext(ctx, &ddb.QueryInput{
+		TableName:              aws.String(models.OnChainTableName),
+		KeyConditionExpression: aws.String("#pk = :s"),
+		ExpressionAttributeNames: map[string]*string{
+			"#pk": aws.String("Series"),
+			"#ts": aws.String("Timestamp"),
+		},
+		ExpressionAttributeValues: map[string]*ddb.AttributeValue{
+			":s": {
+				S: aws.String(series),
+			},
+		},
+		ProjectionExpression: aws.String("#ts"),
+		ScanIndexForward:     aws.Bool(false),
+		Limit:                aws.Int64(1),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(out.Items) == 0 || out.Items[0]["Timestamp"] == nil || out.Items[0]["Timestamp"].N == nil {
+		return 0, nil
+	}
+	return strconv.ParseInt(*out.Items[0]["Timestamp"].N, 10, 64)
+}
+
+func processSeries(ctx context.Context, asset string, metric glassnode.GlassNodeRouteName) error {
+	path, ok := glassnodeClient.MetricPath(metric)
+	if !ok {
+		return &glassnode.RouteNotRecognizedError{Route: string(metric)}
+	}
+	series := models.SeriesKey(asset, metric)
+	latest, err := latestTimestamp(ctx, series)
+	if err != nil {
+		return err
+	}
+	// Only request points newer than the latest one stored
+	options := glassnode.DefaultMetricOptions(path)
+	options.Asset = asset
+	if latest > 0 {
+		options.Since = time.Unix(latest+1, 0)
+	}
+	log.Infof("Retrieving %s since %d", series, latest)
+	t := time.Now()
+	points, err := glassnodeClient.Metrics.GetPoints(ctx, options)
+	if err != nil {
+		return err
+	}
+	// Points without a value would be stored as 0, so they are left out
+	entries, skipped, err := glassnode.EntriesWithValues(points)
+	if err != nil {
+		return err
+	}
+	log.Infof("Retrieved %d datapoints for %s in %.2fs, skipped %d without a value", len(entries), series, time.Now().Sub(t).Seconds(), skipped)
+	// Form the list of requests, skipping anything at or before the latest stored point
+	items := []*models.OnChainEntry{}
+	for _, e := range models.NewOnChainEntries(asset, metric, entries) {
+		if e.Timestamp > latest {
+			items = append(items, e)
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	batchWriter := dynamodbutil.NewBatchWriter(ddbClient)
+	if err := batchWriter.PutItems(items); err != nil {
+		return err
+	}
+	result, err := batchWriter.Flush(ctx)
+	log.Infof("Saved %d datapoints for %s, %d failed", result.Written, series, result.Failed)
+	return err
+}
+
+func handler(ctx context.Context, e OnChainEvent) error {
+	assets := e.Assets
+	if len(assets) == 0 {
+		assets = defaultAssets
+	}
+	metrics := e.Metrics
+	if len(metrics) == 0 {
+		metrics = defaultMetrics
+	}
+	// Keep going when a single series fails so one restricted metric doesn't block the rest
+	var lastErr error
+	for _, metric := range metrics {
+		for _, asset := range assets {
+			if err := processSeries(ctx, asset, metric); err != nil {
+				log.WithFields(logrus.Fields{"asset": asset, "metric": metric}).Errorf("Unable to update series: %v", err)
+				lastErr = err
+			}
+		}
+	}
+	return lastErr
+}
+
+func main() {
+	lambda.Start(handler)
+}
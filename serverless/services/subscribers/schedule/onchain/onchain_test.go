@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mcclurejt/mrkt-backend/api/dynamodbutil/dynamodbtest"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode/glassnodetest"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+)
+
+func TestProcessSeries(t *testing.T) {
+	fixtures := glassnodetest.DefaultFixtures()
+	points := fixtures.Points(glassnodetest.NuplPath, glassnode.BTC)
+	// Glassnode returns null for days it has no data
+	nullTimestamp := points[3].Timestamp
+	points[3].Value = nil
+	server := glassnodetest.NewServer(fixtures)
+	defer server.Close()
+	glassnodeClient = server.Client(glassnodeOptions...)
+	d, err := dynamodbtest.NewFromModels(models.OnChainEntry{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddbClient = d
+
+	if err := processSeries(context.Background(), glassnode.BTC, glassnode.NuplRouteName); err != nil {
+		t.Fatal(err)
+	}
+	items := d.Items(models.OnChainTableName)
+	if len(items) != glassnodetest.DailyPoints-1 {
+		t.Fatalf("got %d stored points, want %d", len(items), glassnodetest.DailyPoints-1)
+	}
+	for _, item := range items {
+		if *item["Timestamp"].N == fmt.Sprint(nullTimestamp) {
+			t.Fatalf("got %v stored, want the null point left out", item)
+		}
+	}
+
+	// a second run only asks for points after the latest one stored, of which there are none
+	if err := processSeries(context.Background(), glassnode.BTC, glassnode.NuplRouteName); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(d.Items(models.OnChainTableName)); n != glassnodetest.DailyPoints-1 {
+		t.Fatalf("got %d stored points after the second run, want %d", n, glassnodetest.DailyPoints-1)
+	}
+	if n := d.Calls(dynamodbtest.OpBatchWriteItem); n != 2 {
+		t.Fatalf("got %d BatchWriteItem calls, want the 2 of the first run only", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := processSeries(ctx, glassnode.BTC, glassnode.NuplRouteName); err == nil {
+		t.Fatal("got no error, want the cancelled context to stop the query")
+	}
+}
@@ -3,17 +3,18 @@ package main
 import (
 	"context"
 	"errors"
-	"fmt"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+	"github.com/mcclurejt/mrkt-backend/api/repository"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	ddb "github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	iex "github.com/goinvest/iexcloud/v2"
 	"github.com/mcclurejt/mrkt-backend/config"
 
@@ -26,9 +27,9 @@ import (
 // https://serverless.com/framework/docs/providers/aws/events/apigateway/#lambda-proxy-integration
 
 var (
-	iexClient *iex.Client
-	ddbClient dynamodbiface.DynamoDBAPI
-	log       *logrus.Logger
+	iexClient         *iex.Client
+	companyRepository repository.CompanyRepository
+	log               *logrus.Logger
 )
 
 func init() {
@@ -40,90 +41,30 @@ func init() {
 	if err != nil {
 		return
 	}
-	ddbClient = ddb.New(awsSession)
+	companyRepository = repository.NewCompanyRepository(ddb.New(awsSession))
 	log = logrus.New()
 }
 
 func processItem(item map[string]events.DynamoDBAttributeValue) error {
 	// Retrieve historical data
-	symbol, ok := item["Symbol"]
+	symbol, ok := item[models.SymbolAttribute]
 	if !ok {
 		return errors.New("Symbol Key Not Found")
 	}
-	log.Info("Retrieving company summary for %s", symbol.String())
+	// Crypto assets have no company summary on IEX
+	if _, ok := glassnode.CryptoAssetFromSymbol(symbol.String()); ok {
+		log.Infof("Skipping company summary for crypto asset %s", symbol.String())
+		return nil
+	}
+	log.Infof("Retrieving company summary for %s", symbol.String())
 	t := time.Now()
 	data, err := iexClient.Company(context.Background(), symbol.String())
 	if err != nil {
 		return err
 	}
 	log.Infof("Retrieved company summary in %.2fs", time.Now().Sub(t).Seconds())
-	// Form the list of requests
-	input := &ddb.PutItemInput{
-		TableName: aws.String("Company"),
-		Item: map[string]*ddb.AttributeValue{
-			"Symbol": {
-				S: aws.String(symbol.String()),
-			},
-			"CompanyName": {
-				S: aws.String(data.Name),
-			},
-			"Exchange": {
-				S: aws.String(data.Exchange),
-			},
-			"Industry": {
-				S: aws.String(data.Industry),
-			},
-			"Website": {
-				S: aws.String(data.Website),
-			},
-			"Description": {
-				S: aws.String(data.Description),
-			},
-			"CEO": {
-				S: aws.String(data.CEO),
-			},
-			"SecurityName": {
-				S: aws.String(data.SecurityName),
-			},
-			"IssueType": {
-				S: aws.String(data.IssueType),
-			},
-			"Sector": {
-				S: aws.String(data.Sector),
-			},
-			"PrimarySicCode": {
-				N: aws.String(fmt.Sprintf("%d", data.PrimarySICCode)),
-			},
-			"Employees": {
-				N: aws.String(fmt.Sprintf("%d", data.Employees)),
-			},
-			"Tags": {
-				SS: aws.StringSlice(data.Tags),
-			},
-			"Address": {
-				S: aws.String(data.Address),
-			},
-			"Address2": {
-				S: aws.String(data.Address2),
-			},
-			"State": {
-				S: aws.String(data.State),
-			},
-			"City": {
-				S: aws.String(data.City),
-			},
-			"Zip": {
-				S: aws.String(data.Zip),
-			},
-			"Country": {
-				S: aws.String(data.Country),
-			},
-			"Phone": {
-				S: aws.String(data.Phone),
-			},
-		},
-	}
-	if _, err := ddbClient.PutItem(input); err != nil {
+	// Save the company summary
+	if err := companyRepository.Put(context.Background(), models.NewCompany(symbol.String(), data)); err != nil {
 		return err
 	}
 	log.Infof("Saved company summary for %s", symbol)
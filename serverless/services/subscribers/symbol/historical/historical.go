@@ -3,23 +3,23 @@ package main
 import (
 	"context"
 	"errors"
-	"fmt"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+	"github.com/mcclurejt/mrkt-backend/api/repository"
 	"github.com/mcclurejt/mrkt-backend/config"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	ddb "github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 
 	iex "github.com/goinvest/iexcloud/v2"
 
 	"github.com/sirupsen/logrus"
-	"golang.org/x/sync/errgroup"
 )
 
 // Response is of type APIGatewayProxyResponse since we're leveraging the
@@ -27,111 +27,92 @@ import (
 //
 // https://serverless.com/framework/docs/providers/aws/events/apigateway/#lambda-proxy-integration
 
+// cryptoHistoricalRange matches the range requested from IEX for equities
+const cryptoHistoricalRange = 6
+
 var (
-	iexClient *iex.Client
-	ddbClient dynamodbiface.DynamoDBAPI
-	log       *logrus.Logger
+	iexClient            *iex.Client
+	glassnodeClient      *glassnode.GlassNodeClient
+	historicalRepository repository.HistoricalRepository
+	log                  *logrus.Logger
 )
 
+// glassnodeOptions - Invalid assets and intervals are rejected against the endpoint catalogue without spending a request
+var glassnodeOptions = []glassnode.ClientOption{glassnode.WithAPIKeyHeader(), glassnode.WithCatalogValidation()}
+
 func init() {
 	conf := config.New() //env
 	iexClient = iex.NewClient(conf.Api.IEXCloudAPIKey)
+	glassnodeClient = glassnode.NewGlassNodeClient(conf.Api.GlassNodeAPIKey, glassnodeOptions...)
 	awsSession, err := session.NewSession(&aws.Config{
 		Region: aws.String("us-west-2")},
 	)
 	if err != nil {
 		return
 	}
-	ddbClient = ddb.New(awsSession)
+	historicalRepository = repository.NewHistoricalRepository(ddb.New(awsSession))
 	log = logrus.New()
 }
 
 func processItem(item map[string]events.DynamoDBAttributeValue) error {
 	// Retrieve historical data
-	symbol, ok := item["Symbol"]
+	symbol, ok := item[models.SymbolAttribute]
 	if !ok {
 		return errors.New("Symbol Key Not Found")
 	}
-	log.Info("Retrieving historical data for %s", symbol.String())
+	log.Infof("Retrieving historical data for %s", symbol.String())
 	t := time.Now()
-	historical, err := iexClient.HistoricalPrices(context.Background(), symbol.String(), iex.SixMonthHistorical, &iex.HistoricalOptions{ChangeFromClose: true})
+	var historical []iex.HistoricalDataPoint
+	var err error
+	if asset, ok := glassnode.CryptoAssetFromSymbol(symbol.String()); ok {
+		historical, err = cryptoHistoricalPrices(context.Background(), asset)
+	} else {
+		historical, err = iexClient.HistoricalPrices(context.Background(), symbol.String(), iex.SixMonthHistorical, &iex.HistoricalOptions{ChangeFromClose: true})
+	}
 	if err != nil {
 		return err
 	}
 	log.Infof("Retrieved %d historical datapoints in %.2fs", len(historical), time.Now().Sub(t).Seconds())
-	// Form the list of requests
-	var change, changePercent float64
-	writeRequests := make([]*ddb.WriteRequest, len(historical))
-	for i, data := range historical {
-		if i == 0 {
-			change = data.Close - data.Open
-			changePercent = change / data.Open
-		} else {
-			change = data.Close - historical[i-1].Close
-			changePercent = change / historical[i-1].Close
-		}
-		writeRequests[i] = &ddb.WriteRequest{
-			PutRequest: &ddb.PutRequest{
-				Item: map[string]*ddb.AttributeValue{
-					"Symbol": {
-						S: aws.String(symbol.String()),
-					},
-					"Date": {
-						S: aws.String(data.Date),
-					},
-					"Open": {
-						N: aws.String(fmt.Sprintf("%f", data.Open)),
-					},
-					"High": {
-						N: aws.String(fmt.Sprintf("%f", data.High)),
-					},
-					"Low": {
-						N: aws.String(fmt.Sprintf("%f", data.Low)),
-					},
-					"Close": {
-						N: aws.String(fmt.Sprintf("%f", data.Close)),
-					},
-					"Volume": {
-						N: aws.String(fmt.Sprintf("%d", data.Volume)),
-					},
-					"Change": {
-						N: aws.String(fmt.Sprintf("%f", change)),
-					},
-					"ChangePercent": {
-						N: aws.String(fmt.Sprintf("%f", changePercent)),
-					},
-				},
-			},
-		}
-	}
-	// Launch goroutines to execute requests in batches of 25
-	errs, _ := errgroup.WithContext(context.Background())
-	for i := 0; i < len(historical); i += 25 {
-		j := i + 25
-		if j > len(historical) {
-			j = len(historical)
-		}
-		reqs := writeRequests[i:j]
-		errs.Go(func() error {
-			return executeBatch(reqs)
-		})
+	// Write in batches, retrying anything DynamoDB leaves unprocessed
+	t = time.Now()
+	result, err := historicalRepository.Put(context.Background(), models.NewHistorical(symbol.String(), historical))
+	if result == nil {
+		return err
 	}
-	// Wait until all requests are done and return
-	return errs.Wait()
+	log.Infof("Wrote %d historical datapoints for %s in %.2fs, %d failed", result.Written, symbol.String(), time.Now().Sub(t).Seconds(), result.Failed)
+	return err
 }
 
-func executeBatch(reqs []*ddb.WriteRequest) error {
-	t := time.Now()
-	batchRequest := &ddb.BatchWriteItemInput{
-		RequestItems: map[string][]*ddb.WriteRequest{
-			"Historical": reqs,
-		},
+// cryptoHistoricalPrices retrieves daily OHLC prices from glassnode in the same
+// shape IEX returns them, so crypto and equities share the Historical schema.
+// Glassnode has no volume for price data so Volume is left at 0
+func cryptoHistoricalPrices(ctx context.Context, asset string) ([]iex.HistoricalDataPoint, error) {
+	path, ok := glassnodeClient.MetricPath(glassnode.PriceOHLCRouteName)
+	if !ok {
+		return nil, &glassnode.RouteNotRecognizedError{Route: string(glassnode.PriceOHLCRouteName)}
 	}
-	if _, err := ddbClient.BatchWriteItem(batchRequest); err != nil {
-		return err
+	options := glassnode.DefaultMetricOptions(path)
+	options.Asset = asset
+	options.Since = time.Now().AddDate(0, -cryptoHistoricalRange, 0)
+	points, err := glassnodeClient.Metrics.GetPoints(ctx, options)
+	if err != nil {
+		return nil, err
 	}
-	log.Infof("Executed batch request in %.2fs", time.Now().Sub(t).Seconds())
-	return nil
+	historical := make([]iex.HistoricalDataPoint, len(points))
+	for i, p := range points {
+		ohlc := glassnode.OHLC{}
+		if err := p.Decode(&ohlc); err != nil {
+			return nil, err
+		}
+		historical[i] = iex.HistoricalDataPoint{
+			Date:  time.Unix(p.Timestamp, 0).UTC().Format("2006-01-02"),
+			Open:  ohlc.Open,
+			High:  ohlc.High,
+			Low:   ohlc.Low,
+			Close: ohlc.Close,
+		}
+	}
+	return historical, nil
 }
 
 func handler(e events.DynamoDBEvent) error {
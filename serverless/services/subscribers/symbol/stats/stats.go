@@ -3,18 +3,19 @@ package main
 import (
 	"context"
 	"errors"
-	"fmt"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/mcclurejt/mrkt-backend/api/glassnode"
+	"github.com/mcclurejt/mrkt-backend/api/models"
+	"github.com/mcclurejt/mrkt-backend/api/repository"
 	"github.com/mcclurejt/mrkt-backend/config"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	ddb "github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 
 	iex "github.com/goinvest/iexcloud/v2"
 
@@ -27,9 +28,9 @@ import (
 // https://serverless.com/framework/docs/providers/aws/events/apigateway/#lambda-proxy-integration
 
 var (
-	iexClient *iex.Client
-	ddbClient dynamodbiface.DynamoDBAPI
-	log       *logrus.Logger
+	iexClient       *iex.Client
+	statsRepository repository.StatsRepository
+	log             *logrus.Logger
 )
 
 func init() {
@@ -41,183 +42,30 @@ func init() {
 	if err != nil {
 		return
 	}
-	ddbClient = ddb.New(awsSession)
+	statsRepository = repository.NewStatsRepository(ddb.New(awsSession))
 	log = logrus.New()
 }
 
 func processItem(item map[string]events.DynamoDBAttributeValue) error {
 	// Retrieve advanced stats
-	symbol, ok := item["Symbol"]
+	symbol, ok := item[models.SymbolAttribute]
 	if !ok {
 		return errors.New("Symbol Key Not Found")
 	}
-	log.Info("Retrieving stats for %s", symbol.String())
+	// Crypto assets have no stats on IEX
+	if _, ok := glassnode.CryptoAssetFromSymbol(symbol.String()); ok {
+		log.Infof("Skipping stats for crypto asset %s", symbol.String())
+		return nil
+	}
+	log.Infof("Retrieving stats for %s", symbol.String())
 	t := time.Now()
 	data, err := iexClient.AdvancedStats(context.Background(), symbol.String())
 	if err != nil {
 		return err
 	}
 	log.Infof("Retrieved stats in %.2fs", time.Now().Sub(t).Seconds())
-	// Form the request
-	input := &ddb.PutItemInput{
-		TableName: aws.String("Stats"),
-		Item: map[string]*ddb.AttributeValue{
-			"Symbol": {
-				S: aws.String(symbol.String()),
-			},
-			"MarketCap": {
-				N: aws.String(fmt.Sprintf("%f", data.MarketCap)),
-			},
-			"Week52High": {
-				N: aws.String(fmt.Sprintf("%f", data.Week52High)),
-			},
-			"Week52Low": {
-				N: aws.String(fmt.Sprintf("%f", data.Week52Low)),
-			},
-			"Week52Change": {
-				N: aws.String(fmt.Sprintf("%f", data.Week52Change)),
-			},
-			"SharesOutstanding": {
-				N: aws.String(fmt.Sprintf("%f", data.SharesOutstanding)),
-			},
-			"Float": {
-				N: aws.String(fmt.Sprintf("%f", data.Float)),
-			},
-			"Avg10Volume": {
-				N: aws.String(fmt.Sprintf("%f", data.Avg10Volume)),
-			},
-			"Avg30Volume": {
-				N: aws.String(fmt.Sprintf("%f", data.Avg30Volume)),
-			},
-			"Day200MovingAvg": {
-				N: aws.String(fmt.Sprintf("%f", data.Day200MovingAvg)),
-			},
-			"Day50MovingAvg": {
-				N: aws.String(fmt.Sprintf("%f", data.Day50MovingAvg)),
-			},
-			"Employees": {
-				N: aws.String(fmt.Sprintf("%d", data.Employees)),
-			},
-			"TTMEPS": {
-				N: aws.String(fmt.Sprintf("%f", data.TTMEPS)),
-			},
-			"TTMDividendRate": {
-				N: aws.String(fmt.Sprintf("%f", data.TTMDividendRate)),
-			},
-			"DividendYield": {
-				N: aws.String(fmt.Sprintf("%f", data.DividendYield)),
-			},
-			"NextDividendDate": {
-				S: aws.String(time.Time(data.NextDividendDate).Format("2006-01-02")),
-			},
-			"ExDividendDate": {
-				S: aws.String(time.Time(data.ExDividendDate).Format("2006-01-02")),
-			},
-			"NextEarningsDate": {
-				S: aws.String(time.Time(data.NextEarningsDate).Format("2006-01-02")),
-			},
-			"PERatio": {
-				N: aws.String(fmt.Sprintf("%f", data.PERatio)),
-			},
-			"Beta": {
-				N: aws.String(fmt.Sprintf("%f", data.Beta)),
-			},
-			"MaxChangePercent": {
-				N: aws.String(fmt.Sprintf("%f", data.MaxChangePercent)),
-			},
-			"Year5ChangePercent": {
-				N: aws.String(fmt.Sprintf("%f", data.Year5ChangePercent)),
-			},
-			"Year2ChangePercent": {
-				N: aws.String(fmt.Sprintf("%f", data.Year2ChangePercent)),
-			},
-			"Year1ChangePercent": {
-				N: aws.String(fmt.Sprintf("%f", data.Year1ChangePercent)),
-			},
-			"YTDChangePercent": {
-				N: aws.String(fmt.Sprintf("%f", data.YTDChangePercent)),
-			},
-			"Month6ChangePercent": {
-				N: aws.String(fmt.Sprintf("%f", data.Month6ChangePercent)),
-			},
-			"Month3ChangePercent": {
-				N: aws.String(fmt.Sprintf("%f", data.Month3ChangePercent)),
-			},
-			"Month1ChangePercent": {
-				N: aws.String(fmt.Sprintf("%f", data.Month1ChangePercent)),
-			},
-			"Day30ChangePercent": {
-				N: aws.String(fmt.Sprintf("%f", data.Day30ChangePercent)),
-			},
-			"Day5ChangePercent": {
-				N: aws.String(fmt.Sprintf("%f", data.Day5ChangePercent)),
-			},
-			"TotalCash": {
-				N: aws.String(fmt.Sprintf("%f", data.TotalCash)),
-			},
-			"CurrentDebt": {
-				N: aws.String(fmt.Sprintf("%f", data.CurrentDebt)),
-			},
-			"Revenue": {
-				N: aws.String(fmt.Sprintf("%f", data.Revenue)),
-			},
-			"GrossProfit": {
-				N: aws.String(fmt.Sprintf("%f", data.GrossProfit)),
-			},
-			"TotalRevenue": {
-				N: aws.String(fmt.Sprintf("%f", data.TotalRevenue)),
-			},
-			"EBITDA": {
-				N: aws.String(fmt.Sprintf("%f", data.EBITDA)),
-			},
-			"RevenuePerShare": {
-				N: aws.String(fmt.Sprintf("%f", data.RevenuePerShare)),
-			},
-			"RevenuePerEmployee": {
-				N: aws.String(fmt.Sprintf("%f", data.RevenuePerEmployee)),
-			},
-			"DebtToEquity": {
-				N: aws.String(fmt.Sprintf("%f", data.DebtToEquity)),
-			},
-			"ProfitMargin": {
-				N: aws.String(fmt.Sprintf("%f", data.ProfitMargin)),
-			},
-			"EnterpriseValue": {
-				N: aws.String(fmt.Sprintf("%f", data.EnterpriseValue)),
-			},
-			"EnterpriseValueToRevenue": {
-				N: aws.String(fmt.Sprintf("%f", data.EnterpriseValueToRevenue)),
-			},
-			"PriceToSales": {
-				N: aws.String(fmt.Sprintf("%f", data.PriceToSales)),
-			},
-			"PriceToBook": {
-				N: aws.String(fmt.Sprintf("%f", data.PriceToBook)),
-			},
-			"ForwardPERatio": {
-				N: aws.String(fmt.Sprintf("%f", data.ForwardPERatio)),
-			},
-			"PEGRatio": {
-				N: aws.String(fmt.Sprintf("%f", data.PEGRatio)),
-			},
-			"PEHigh": {
-				N: aws.String(fmt.Sprintf("%f", data.PEHigh)),
-			},
-			"PELow": {
-				N: aws.String(fmt.Sprintf("%f", data.PELow)),
-			},
-			"Week52HighDate": {
-				S: aws.String(time.Time(data.Week52HighDate).Format("2006-01-02")),
-			},
-			"Week52LowDate": {
-				S: aws.String(time.Time(data.Week52LowDate).Format("2006-01-02")),
-			},
-			"PutCallRatio": {
-				N: aws.String(fmt.Sprintf("%f", data.PutCallRatio)),
-			},
-		},
-	}
-	if _, err := ddbClient.PutItem(input); err != nil {
+	// Save the stats
+	if err := statsRepository.Put(context.Background(), models.NewStats(symbol.String(), data)); err != nil {
 		return err
 	}
 	log.Infof("Saved stats for %s", symbol)